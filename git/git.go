@@ -2,9 +2,11 @@ package git
 
 import (
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -143,6 +145,62 @@ func GetConfig(key string) (string, error) {
 	return repo.GetConfig(key)
 }
 
+// GetConfigRegexp returns every config entry whose key matches the regular
+// expression, keyed by the lowercased key name. Includes (e.g. includeIf) are
+// honored, and for multi-valued keys the last value wins.
+func (repo *Repository) GetConfigRegexp(pattern string) (map[string]string, error) {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %v", err)
+	}
+
+	cmd := exec.Command("git", "config", "--null", "--get-regexp", pattern)
+	cmd.Dir = workTree.Filesystem.Root()
+	start := time.Now()
+	out, err := cmd.Output()
+	logCommand(cmd, start, err)
+	if err != nil {
+		// git exits with status 1 when no key matches
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("error running git command: `%s`: %v", cmd.String(), err)
+	}
+
+	entries := make(map[string]string)
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if entry == "" {
+			continue
+		}
+		// Each entry is "key\nvalue", or just "key" for a valueless boolean
+		key, value, found := strings.Cut(entry, "\n")
+		if !found {
+			value = "true"
+		}
+		entries[key] = value
+	}
+	return entries, nil
+}
+
+// GetConfigRegexp returns the config entries of the current repository whose
+// key matches the regular expression, see Repository.GetConfigRegexp
+func GetConfigRegexp(pattern string) (map[string]string, error) {
+	repo, err := GetRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetConfigRegexp(pattern)
+}
+
+// logCommand logs a finished git command at debug level with its duration
+func logCommand(cmd *exec.Cmd, start time.Time, err error) {
+	attrs := []any{"args", strings.Join(cmd.Args[1:], " "), "dir", cmd.Dir, "duration", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("git", attrs...)
+}
+
 // Exec on a repository
 func (repo *Repository) GitExec(args ...string) (string, error) {
 	workTree, err := repo.Worktree()
@@ -151,7 +209,9 @@ func (repo *Repository) GitExec(args ...string) (string, error) {
 	}
 	cmd := exec.Command("git", args...)
 	cmd.Dir = workTree.Filesystem.Root()
+	start := time.Now()
 	out, err := cmd.CombinedOutput()
+	logCommand(cmd, start, err)
 	if err != nil {
 		return "", fmt.Errorf("error running git command: `%s` \n %s", cmd.String(), out)
 	}
@@ -167,7 +227,7 @@ func RefExec[T any](repo *Repository, inner func(*object.Commit) T, parent strin
 		"--reverse",
 	)
 	if err != nil {
-		fmt.Printf("Error getting ref exec: %v\n", err)
+		slog.Warn("failed to list commits", "range", parent+"..HEAD", "err", err)
 		return nil
 	}
 	lines := strings.Split(out, "\n")
@@ -177,7 +237,7 @@ func RefExec[T any](repo *Repository, inner func(*object.Commit) T, parent strin
 			hash := plumbing.NewHash(line)
 			commit, err := repo.CommitObject(hash)
 			if err != nil {
-				fmt.Printf("Error getting commit: %v\n", err)
+				slog.Warn("failed to read commit", "hash", line, "err", err)
 				continue
 			}
 			result := inner(commit)
@@ -195,7 +255,7 @@ func (repo *Repository) RefSummaries(parent string) []string {
 		"--reverse",
 	)
 	if err != nil {
-		fmt.Printf("Error getting summary on refs: %v\n", err)
+		slog.Warn("failed to list commit summaries", "range", parent+"..HEAD", "err", err)
 		return nil
 	}
 	lines := strings.Split(out, "\n")
@@ -1,6 +1,9 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -142,6 +145,41 @@ func TestGetConfig(t *testing.T) {
 	})
 }
 
+func TestGetConfigRegexp(t *testing.T) {
+	testRepo := NewTestRepo(t)
+	defer testRepo.Cleanup()
+
+	// Settings pulled in through includeIf must be visible too
+	includedPath := filepath.Join(t.TempDir(), "work.gitconfig")
+	if err := os.WriteFile(includedPath, []byte("[review]\n\tdraft = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write included config: %v", err)
+	}
+	testRepo.GitExec("config", "includeIf.gitdir:"+testRepo.Dir+"/.path", includedPath)
+	testRepo.GitExec("config", "review.Labels", "a,b")
+	testRepo.GitExec("config", "review.multi-line", "first\nsecond")
+
+	testRepo.InDir(func() {
+		entries, err := GetConfigRegexp(`^review\.`)
+		if err != nil {
+			t.Fatalf("GetConfigRegexp failed: %v", err)
+		}
+
+		expected := map[string]string{
+			"review.draft":      "true",
+			"review.labels":     "a,b",
+			"review.multi-line": "first\nsecond",
+		}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("GetConfigRegexp() = %v, expected %v", entries, expected)
+		}
+
+		entries, err = GetConfigRegexp(`^nothing\.matches$`)
+		if err != nil || len(entries) != 0 {
+			t.Errorf("Expected no entries and no error, got %v, %v", entries, err)
+		}
+	})
+}
+
 func TestGitConfigScopes(t *testing.T) {
 	testRepo := NewTestRepo(t)
 	defer testRepo.Cleanup()
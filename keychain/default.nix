@@ -4,7 +4,7 @@ buildGoModule {
   pname = "git-keychain";
   src = ./.;
 
-  vendorHash = "sha256-LI/OTwtHOh7HQ7z/xUCBzkA1j0odgb1PWUfJEd0gWA0=";
+  vendorHash = "sha256-bqFeNUeyCl6p5ZbNsb1Nsj+8sBqmiQc2t8jGzyIpVlI=";
 
   subPackages = [ "." ];
 
@@ -0,0 +1,105 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// service and account identify the token in every backend
+	service = "git-review"
+	account = "github-token"
+	label   = "GitHub Token for git-review"
+)
+
+// Backend names, as used by GIT_KEYCHAIN_BACKEND and git config keychain.backend
+const (
+	BackendAuto          = "auto"
+	BackendMacOS         = "macos"
+	BackendSecretService = "secret-service"
+	BackendFile          = "file"
+)
+
+// ErrNotFound is returned by Backend.Get when no token is stored
+var ErrNotFound = errors.New("no token stored")
+
+// Backend stores the GitHub token
+type Backend interface {
+	// Name returns the backend name, e.g. "secret-service"
+	Name() string
+	// Get returns the stored token or ErrNotFound
+	Get() (string, error)
+	// Store replaces the stored token
+	Store(token string) error
+}
+
+// HasToken reports whether backend has a stored token. Backends that can
+// tell without reading the token, like the encrypted file, are not asked for
+// it, so no passphrase is prompted for.
+func HasToken(backend Backend) bool {
+	if checker, ok := backend.(interface{ Exists() bool }); ok {
+		return checker.Exists()
+	}
+	_, err := backend.Get()
+	return err == nil
+}
+
+// Backends returns the names that can be passed to NewBackend
+func Backends() []string {
+	return []string{BackendAuto, BackendMacOS, BackendSecretService, BackendFile}
+}
+
+// NewBackend returns the backend called name. "auto" and "" pick the macOS
+// keychain on macOS, the Secret Service when secret-tool and a D-Bus session
+// are available, and the encrypted file otherwise.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case BackendAuto, "":
+		if runtime.GOOS == "darwin" {
+			return securityBackend{}, nil
+		}
+		reason := SecretServiceUnavailable()
+		if reason == "" {
+			return secretToolBackend{}, nil
+		}
+		slog.Debug("not using the Secret Service", "reason", reason)
+		return NewFileBackend("")
+	case BackendMacOS:
+		return securityBackend{}, nil
+	case BackendSecretService:
+		return secretToolBackend{}, nil
+	case BackendFile:
+		return NewFileBackend(gitConfig("keychain.file"))
+	}
+	return nil, fmt.Errorf("unknown keychain backend %q, expected one of %s", name, strings.Join(Backends(), ", "))
+}
+
+// ConfiguredBackend returns the backend named by GIT_KEYCHAIN_BACKEND or git
+// config keychain.backend, "" if neither is set
+func ConfiguredBackend() string {
+	if name := os.Getenv("GIT_KEYCHAIN_BACKEND"); name != "" {
+		return name
+	}
+	return gitConfig("keychain.backend")
+}
+
+// SelectBackend returns the backend named by GIT_KEYCHAIN_BACKEND or git
+// config keychain.backend, choosing one automatically if neither is set
+func SelectBackend() (Backend, error) {
+	return NewBackend(ConfiguredBackend())
+}
+
+// gitConfig returns the value of a git config key, or "" if it is not set or
+// git is not available
+func gitConfig(key string) string {
+	output, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
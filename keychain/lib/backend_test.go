@@ -0,0 +1,242 @@
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeSecretTool puts a secret-tool on PATH that keeps the secret in a file
+// next to it, and logs its arguments
+func fakeSecretTool(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/args"
+secret="$(dirname "$0")/secret"
+case "$1" in
+lookup) [ -f "$secret" ] || exit 1; cat "$secret" ;;
+store) cat > "$secret" ;;
+*) echo "unexpected command $1" >&2; exit 2 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake secret-tool: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "github-token.enc")
+	passphrase := "correct horse"
+	backend := &FileBackend{Path: path, Passphrase: func(bool) (string, error) { return passphrase, nil }}
+
+	if _, err := backend.Get(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() without a file = %v, expected ErrNotFound", err)
+	}
+
+	if err := backend.Store("ghp_secret"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	token, err := backend.Get()
+	if err != nil || token != "ghp_secret" {
+		t.Errorf("Get() = %q, %v, expected \"ghp_secret\"", token, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read token file: %v", err)
+	}
+	if strings.Contains(string(content), "ghp_secret") {
+		t.Errorf("token file contains the token in plain text")
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, expected 0600", info.Mode().Perm())
+	}
+
+	passphrase = "wrong"
+	if _, err := backend.Get(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Get() with a wrong passphrase = %v, expected a decryption error", err)
+	}
+
+	if err := os.WriteFile(path, []byte("ghp_plain"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Get(); err == nil || !strings.Contains(err.Error(), "not a git-keychain token file") {
+		t.Errorf("Get() of a plain file = %v, expected a format error", err)
+	}
+}
+
+func TestHasTokenWithoutPassphrase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_KEYCHAIN_BACKEND", BackendFile)
+	t.Setenv("GIT_KEYCHAIN_PASSPHRASE", "")
+
+	if HasExistingToken() {
+		t.Errorf("HasExistingToken() = true without a token file")
+	}
+
+	backend, err := NewFileBackend("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Passphrase = func(bool) (string, error) { return "correct horse", nil }
+	if err := backend.Store("ghp_secret"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	// Without GIT_KEYCHAIN_PASSPHRASE or a terminal, reading the token fails
+	if _, err := GetTokenFromKeychain(); err == nil {
+		t.Fatalf("expected reading the token without a passphrase to fail")
+	}
+	if !HasExistingToken() {
+		t.Errorf("HasExistingToken() = false, expected the token file to be found without its passphrase")
+	}
+}
+
+func TestFileBackendPassphraseFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_KEYCHAIN_PASSPHRASE", "from env")
+	backend, err := NewFileBackend("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(backend.Path, filepath.Join(".config", "git-review", "github-token.enc")) {
+		t.Errorf("Path = %q, expected the git-review config directory", backend.Path)
+	}
+	if err := backend.Store("ghp_env"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if token, err := backend.Get(); err != nil || token != "ghp_env" {
+		t.Errorf("Get() = %q, %v, expected \"ghp_env\"", token, err)
+	}
+}
+
+func TestSecretToolBackend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake secret-tool is a shell script")
+	}
+	dir := fakeSecretTool(t)
+	backend := secretToolBackend{}
+
+	if _, err := backend.Get(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() before Store() = %v, expected ErrNotFound", err)
+	}
+	if err := backend.Store("ghp_dbus"); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if token, err := backend.Get(); err != nil || token != "ghp_dbus" {
+		t.Errorf("Get() = %q, %v, expected \"ghp_dbus\"", token, err)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(args), "ghp_dbus") {
+		t.Errorf("token passed on the command line: %s", args)
+	}
+	if !strings.Contains(string(args), "store --label=GitHub Token for git-review service git-review account github-token") {
+		t.Errorf("unexpected secret-tool arguments: %s", args)
+	}
+}
+
+func TestSelectBackend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake secret-tool is a shell script")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	fakeSecretTool(t)
+
+	auto := BackendFile
+	if runtime.GOOS == "darwin" {
+		auto = BackendMacOS
+	}
+	autoWithDBus := BackendSecretService
+	if runtime.GOOS == "darwin" {
+		autoWithDBus = BackendMacOS
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		dbus     string
+		expected string
+		err      string
+	}{
+		{"AutoWithoutDBus", "", "", auto, ""},
+		{"AutoWithDBus", "auto", "unix:path=/run/user/1000/bus", autoWithDBus, ""},
+		{"MacOS", "macos", "", BackendMacOS, ""},
+		{"SecretService", "secret-service", "", BackendSecretService, ""},
+		{"File", "file", "unix:path=/run/user/1000/bus", BackendFile, ""},
+		{"Unknown", "pass", "", "", `unknown keychain backend "pass"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GIT_KEYCHAIN_BACKEND", test.env)
+			t.Setenv("DBUS_SESSION_BUS_ADDRESS", test.dbus)
+			backend, err := SelectBackend()
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("SelectBackend() error = %v, expected %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectBackend() failed: %v", err)
+			}
+			if backend.Name() != test.expected {
+				t.Errorf("SelectBackend() = %s, expected %s", backend.Name(), test.expected)
+			}
+		})
+	}
+}
+
+func TestSecretServiceUnavailable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake secret-tool is a shell script")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_KEYCHAIN_BACKEND", "")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/run/user/1000/bus")
+
+	t.Setenv("PATH", t.TempDir())
+	if reason := SecretServiceUnavailable(); !strings.Contains(reason, "secret-tool is not installed") {
+		t.Errorf("SecretServiceUnavailable() without secret-tool = %q", reason)
+	}
+	if runtime.GOOS != "darwin" {
+		var logs bytes.Buffer
+		defaultLogger := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		defer slog.SetDefault(defaultLogger)
+
+		backend, err := SelectBackend()
+		if err != nil {
+			t.Fatalf("SelectBackend() failed: %v", err)
+		}
+		if backend.Name() != BackendFile {
+			t.Errorf("SelectBackend() = %s, expected %s", backend.Name(), BackendFile)
+		}
+		if !strings.Contains(logs.String(), "secret-tool is not installed") {
+			t.Errorf("expected the reason for the file backend in the debug log, got %q", logs.String())
+		}
+	}
+
+	fakeSecretTool(t)
+	if reason := SecretServiceUnavailable(); reason != "" {
+		t.Errorf("SecretServiceUnavailable() with secret-tool and D-Bus = %q, expected \"\"", reason)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	if reason := SecretServiceUnavailable(); !strings.Contains(reason, "DBUS_SESSION_BUS_ADDRESS") {
+		t.Errorf("SecretServiceUnavailable() without D-Bus = %q", reason)
+	}
+}
@@ -0,0 +1,186 @@
+package keychain
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// fileMagic starts every token file and versions its format
+var fileMagic = []byte("GKC1")
+
+const (
+	saltSize = 16
+	// scrypt parameters, the 2017 recommendation for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// FileBackend stores the token in a file encrypted with AES-256-GCM, under a
+// key derived from a passphrase with scrypt. It is the fallback on systems
+// without a keychain.
+type FileBackend struct {
+	Path string
+	// Passphrase returns the passphrase, confirm is set when storing a new
+	// token
+	Passphrase func(confirm bool) (string, error)
+}
+
+// NewFileBackend returns a file backend storing the token at path, by default
+// ~/.config/git-review/github-token.enc. The passphrase is read from
+// GIT_KEYCHAIN_PASSPHRASE or prompted for on the terminal.
+func NewFileBackend(path string) (*FileBackend, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the token file: %v", err)
+		}
+		path = filepath.Join(home, ".config", "git-review", "github-token.enc")
+	}
+	return &FileBackend{Path: path, Passphrase: promptPassphrase}, nil
+}
+
+func (b *FileBackend) Name() string { return BackendFile }
+
+// Exists reports whether the token file exists, without decrypting it
+func (b *FileBackend) Exists() bool {
+	_, err := os.Stat(b.Path)
+	return err == nil
+}
+
+func (b *FileBackend) Get() (string, error) {
+	data, err := os.ReadFile(b.Path)
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", b.Path, err)
+	}
+	if !bytes.HasPrefix(data, fileMagic) || len(data) < len(fileMagic)+saltSize {
+		return "", fmt.Errorf("%s is not a git-keychain token file", b.Path)
+	}
+	salt := data[len(fileMagic) : len(fileMagic)+saltSize]
+	sealed := data[len(fileMagic)+saltSize:]
+
+	passphrase, err := b.Passphrase(false)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%s is truncated", b.Path)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	token, err := aead.Open(nil, nonce, ciphertext, fileMagic)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: wrong passphrase or corrupted file", b.Path)
+	}
+	return string(token), nil
+}
+
+func (b *FileBackend) Store(token string) error {
+	passphrase, err := b.Passphrase(true)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return fmt.Errorf("an empty passphrase is not allowed")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(append(append([]byte{}, fileMagic...), salt...), nonce...)
+	data = aead.Seal(data, nonce, []byte(token), fileMagic)
+
+	if err := os.MkdirAll(filepath.Dir(b.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(b.Path), err)
+	}
+	// Write to a temporary file first so a failed write keeps the old token
+	tmp, err := os.CreateTemp(filepath.Dir(b.Path), ".github-token-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", b.Path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", b.Path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", b.Path, err)
+	}
+	if err := os.Rename(tmp.Name(), b.Path); err != nil {
+		return fmt.Errorf("failed to write %s: %v", b.Path, err)
+	}
+	return nil
+}
+
+// newAEAD derives the file key from passphrase and salt
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// promptPassphrase returns GIT_KEYCHAIN_PASSPHRASE or asks for the passphrase
+// on the terminal, twice when confirm is set
+func promptPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv("GIT_KEYCHAIN_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("the token file is encrypted: set GIT_KEYCHAIN_PASSPHRASE or run from a terminal")
+	}
+
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %v", err)
+		}
+		return strings.TrimRight(string(passphrase), "\r\n"), nil
+	}
+
+	passphrase, err := read("Token file passphrase: ")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := read("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
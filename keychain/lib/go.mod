@@ -1,3 +1,11 @@
 module github.com/jtamagnan/git-utils/keychain/lib
 
-go 1.21
+go 1.24.1
+
+require (
+	golang.org/x/crypto v0.37.0
+	golang.org/x/term v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.32.0 // indirect
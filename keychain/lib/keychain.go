@@ -1,79 +1,42 @@
 package keychain
 
 import (
-	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 )
 
-// GetGitHubToken retrieves the GitHub token from keychain or environment
+// GetGitHubToken retrieves the GitHub token for github.com from the
+// configured token sources
 func GetGitHubToken() (string, error) {
-	// First try macOS keychain
-	if token, err := GetTokenFromKeychain(); err == nil && token != "" {
-		return token, nil
-	}
-
-	// Fall back to environment variable
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return token, nil
-	}
-
-	return "", fmt.Errorf("GitHub token not found. Please either:\n" +
-		"  1. Add token to keychain: go run ./keychain\n" +
-		"  2. Set environment variable: export GITHUB_TOKEN=your_token")
+	token, _, err := GetHostToken(DefaultHost)
+	return token, err
 }
 
-// GetTokenFromKeychain retrieves the GitHub token from macOS keychain
+// GetTokenFromKeychain retrieves the GitHub token from the selected backend
 func GetTokenFromKeychain() (string, error) {
-	cmd := exec.Command("security", "find-generic-password",
-		"-s", "git-review", // service name
-		"-a", "github-token", // account name
-		"-w") // return password only
-
-	output, err := cmd.Output()
+	backend, err := SelectBackend()
 	if err != nil {
 		return "", err
 	}
-
-	token := strings.TrimSpace(string(output))
-	if token == "" {
-		return "", fmt.Errorf("empty token in keychain")
-	}
-
-	return token, nil
+	return backend.Get()
 }
 
-// StoreTokenInKeychain stores a GitHub token in the macOS keychain
+// StoreTokenInKeychain stores a GitHub token in the selected backend
 func StoreTokenInKeychain(token string) error {
-	// Delete existing entry if it exists
-	deleteCmd := exec.Command("security", "delete-generic-password",
-		"-s", "git-review",
-		"-a", "github-token")
-	_ = deleteCmd.Run() // Ignore errors - entry might not exist
-
-	// Add new entry
-	cmd := exec.Command("security", "add-generic-password",
-		"-s", "git-review", // service name
-		"-a", "github-token", // account name
-		"-l", "GitHub Token for git-review", // label (shown in Keychain Access)
-		"-D", "application password", // kind
-		"-w", token) // password (the token)
-
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("security command failed: %v\nOutput: %s", err, output)
+	backend, err := SelectBackend()
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return backend.Store(token)
 }
 
-// HasExistingToken checks if a GitHub token already exists in the keychain
+// HasExistingToken checks if a GitHub token already exists in the selected
+// backend, without asking for the file's passphrase
 func HasExistingToken() bool {
-	cmd := exec.Command("security", "find-generic-password",
-		"-s", "git-review",
-		"-a", "github-token")
-
-	return cmd.Run() == nil
+	backend, err := SelectBackend()
+	if err != nil {
+		return false
+	}
+	return HasToken(backend)
 }
 
 // IsValidGitHubToken checks if a token matches the expected GitHub format
@@ -7,6 +7,10 @@ import (
 )
 
 func TestGetGitHubTokenSources(t *testing.T) {
+	// Only the keychain and GITHUB_TOKEN are expected to hold a token
+	t.Setenv("GIT_KEYCHAIN_SOURCES", "keychain,env")
+	t.Setenv("GH_TOKEN", "")
+
 	// Save original environment variable
 	originalToken := os.Getenv("GITHUB_TOKEN")
 	defer func() {
@@ -97,6 +101,10 @@ func TestHasExistingTokenHandlesErrors(t *testing.T) {
 }
 
 func TestErrorMessageFormat(t *testing.T) {
+	// Only the keychain and GITHUB_TOKEN are expected to hold a token
+	t.Setenv("GIT_KEYCHAIN_SOURCES", "keychain,env")
+	t.Setenv("GH_TOKEN", "")
+
 	// Save original GITHUB_TOKEN
 	originalToken := os.Getenv("GITHUB_TOKEN")
 	defer func() {
@@ -0,0 +1,63 @@
+package keychain
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretToolBackend stores the token with the freedesktop Secret Service
+// (GNOME Keyring, KWallet, KeePassXC) through libsecret's secret-tool, which
+// talks to the service over the D-Bus session bus
+type secretToolBackend struct{}
+
+// SecretServiceUnavailable returns why the Secret Service backend cannot be
+// used, secret-tool missing or no D-Bus session to reach the service on, or
+// "" if it can
+func SecretServiceUnavailable() string {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "secret-tool is not installed, it comes with libsecret-tools"
+	}
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return "DBUS_SESSION_BUS_ADDRESS is not set"
+	}
+	return ""
+}
+
+func (secretToolBackend) Name() string { return BackendSecretService }
+
+func (secretToolBackend) Get() (string, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		// secret-tool exits 1 without output when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+			return "", ErrNotFound
+		}
+		return "", secretToolError(err)
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (secretToolBackend) Store(token string) error {
+	// The secret is read from stdin so it does not show up in the process list
+	cmd := exec.Command("secret-tool", "store", "--label="+label, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(token)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store failed: %v\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+// secretToolError adds secret-tool's error output to err
+func secretToolError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return fmt.Errorf("secret-tool lookup failed: %v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return fmt.Errorf("secret-tool lookup failed: %v", err)
+}
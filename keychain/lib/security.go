@@ -0,0 +1,57 @@
+package keychain
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityBackend stores the token in the macOS keychain through the
+// security command
+type securityBackend struct{}
+
+func (securityBackend) Name() string { return BackendMacOS }
+
+func (securityBackend) Get() (string, error) {
+	cmd := exec.Command("security", "find-generic-password",
+		"-s", service, // service name
+		"-a", account, // account name
+		"-w") // return password only
+
+	output, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("empty token in keychain")
+	}
+
+	return token, nil
+}
+
+func (securityBackend) Store(token string) error {
+	// Delete existing entry if it exists
+	deleteCmd := exec.Command("security", "delete-generic-password",
+		"-s", service,
+		"-a", account)
+	_ = deleteCmd.Run() // Ignore errors - entry might not exist
+
+	// Add new entry
+	cmd := exec.Command("security", "add-generic-password",
+		"-s", service, // service name
+		"-a", account, // account name
+		"-l", label, // label (shown in Keychain Access)
+		"-D", "application password", // kind
+		"-w", token) // password (the token)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("security command failed: %v\nOutput: %s", err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Token source names, as used by GIT_KEYCHAIN_SOURCES and git config
+// keychain.sources
+const (
+	SourceKeychain   = "keychain"
+	SourceEnv        = "env"
+	SourceGH         = "gh"
+	SourceCredential = "credential"
+)
+
+// DefaultHost is the host git-review talks to
+const DefaultHost = "github.com"
+
+// Sources returns the token sources in their default precedence order
+func Sources() []string {
+	return []string{SourceKeychain, SourceEnv, SourceGH, SourceCredential}
+}
+
+// TokenSources returns the token sources to try, in order, from
+// GIT_KEYCHAIN_SOURCES or git config keychain.sources, e.g. "env,gh"
+func TokenSources() ([]string, error) {
+	value := os.Getenv("GIT_KEYCHAIN_SOURCES")
+	if value == "" {
+		value = gitConfig("keychain.sources")
+	}
+	if value == "" {
+		return Sources(), nil
+	}
+
+	var sources []string
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		known := false
+		for _, name := range Sources() {
+			known = known || source == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown token source %q in keychain.sources, expected %s", source, strings.Join(Sources(), ", "))
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// cachedToken is a token found by a source that is slow or prompts
+type cachedToken struct {
+	token  string
+	source string
+}
+
+var (
+	cacheMu sync.Mutex
+	// cache holds the tokens found per host outside the environment, so the
+	// keychain passphrase or a credential helper is only asked once
+	cache = map[string]cachedToken{}
+)
+
+// GetHostToken returns the token for host from the first source that has
+// one, and a description of that source
+func GetHostToken(host string) (token, source string, err error) {
+	sources, err := TokenSources()
+	if err != nil {
+		return "", "", err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	var errs []error
+	for _, name := range sources {
+		if cached, ok := cache[host]; ok && cached.source == name {
+			return cached.token, cached.source, nil
+		}
+		token, source, err := lookupToken(name, host)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				errs = append(errs, fmt.Errorf("%s: %v", name, err))
+			}
+			continue
+		}
+		slog.Debug("using GitHub credentials", "host", host, "source", source)
+		if name != SourceEnv {
+			cache[host] = cachedToken{token: token, source: name}
+		}
+		return token, source, nil
+	}
+
+	for _, err := range errs {
+		slog.Warn("failed to read the GitHub token", "err", err)
+	}
+	return "", "", fmt.Errorf("GitHub token not found. Please either:\n" +
+		"  1. Add token to keychain: go run ./keychain\n" +
+		"  2. Set environment variable: export GITHUB_TOKEN=your_token\n" +
+		"  3. Log in with the GitHub CLI: gh auth login\n" +
+		"  4. Store it with a git credential helper")
+}
+
+// lookupToken returns the token source name has for host, or ErrNotFound
+func lookupToken(name, host string) (token, source string, err error) {
+	switch name {
+	case SourceKeychain:
+		backend, err := SelectBackend()
+		if err != nil {
+			return "", "", err
+		}
+		token, err := backend.Get()
+		return token, "keychain (" + backend.Name() + ")", err
+	case SourceEnv:
+		return envToken(host)
+	case SourceGH:
+		return ghToken(host)
+	case SourceCredential:
+		token, err := credentialToken(host)
+		return token, "git credential fill", err
+	}
+	return "", "", ErrNotFound
+}
+
+// hostEnvVar returns the per-host token variable, e.g. GITHUB_TOKEN_GITHUB_COM
+func hostEnvVar(host string) string {
+	name := strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, host)
+	return "GITHUB_TOKEN_" + strings.ToUpper(name)
+}
+
+// envToken reads the per-host variable, then GH_TOKEN and GITHUB_TOKEN for
+// github.com or GH_ENTERPRISE_TOKEN and GITHUB_ENTERPRISE_TOKEN for other
+// hosts, like the gh CLI does
+func envToken(host string) (string, string, error) {
+	names := []string{hostEnvVar(host), "GH_TOKEN", "GITHUB_TOKEN"}
+	if host != DefaultHost {
+		names = []string{hostEnvVar(host), "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, name := range names {
+		if token := os.Getenv(name); token != "" {
+			return token, name, nil
+		}
+	}
+	return "", "", ErrNotFound
+}
+
+// ghConfigDir returns the directory of the gh CLI config
+func ghConfigDir() (string, error) {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh"), nil
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gh"), nil
+}
+
+// ghToken reads the token gh auth login stored for host. Recent gh versions
+// keep it in the system keyring rather than hosts.yml, it is then asked from
+// gh itself.
+func ghToken(host string) (string, string, error) {
+	dir, err := ghConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	path := filepath.Join(dir, "hosts.yml")
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(content, &hosts); err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	entry, ok := hosts[host]
+	if !ok {
+		return "", "", ErrNotFound
+	}
+	if entry.OAuthToken != "" {
+		return entry.OAuthToken, "gh " + path, nil
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", "", ErrNotFound
+	}
+	output, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return "", "", ErrNotFound
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", "", ErrNotFound
+	}
+	return token, "gh auth token", nil
+}
+
+// credentialToken asks git's credential helpers for the password of
+// https://host, without letting git prompt for one
+func credentialToken(host string) (string, error) {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	// An empty GIT_ASKPASS also stops git from falling back to SSH_ASKPASS
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=")
+	output, err := cmd.Output()
+	if err != nil {
+		// git fails when no helper has a credential and it may not prompt
+		return "", ErrNotFound
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if password, ok := strings.CutPrefix(line, "password="); ok && password != "" {
+			return password, nil
+		}
+	}
+	return "", ErrNotFound
+}
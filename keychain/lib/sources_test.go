@@ -0,0 +1,191 @@
+package keychain
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// isolateSources points every token source at empty temporary locations
+func isolateSources(t *testing.T) (home string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GH_CONFIG_DIR", filepath.Join(home, "gh"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_KEYCHAIN_BACKEND", BackendFile)
+	t.Setenv("GIT_KEYCHAIN_PASSPHRASE", "test passphrase")
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "GITHUB_TOKEN_GITHUB_COM"} {
+		t.Setenv(name, "")
+	}
+	cacheMu.Lock()
+	cache = map[string]cachedToken{}
+	cacheMu.Unlock()
+	return home
+}
+
+func TestTokenSources(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+		err      string
+	}{
+		{"", Sources(), ""},
+		{"env", []string{"env"}, ""},
+		{"gh, credential,,env", []string{"gh", "credential", "env"}, ""},
+		{"env,vault", nil, `unknown token source "vault"`},
+	}
+
+	for _, test := range tests {
+		isolateSources(t)
+		t.Setenv("GIT_KEYCHAIN_SOURCES", test.value)
+		sources, err := TokenSources()
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("TokenSources(%q) error = %v, expected %q", test.value, err, test.err)
+			}
+			continue
+		}
+		if err != nil || strings.Join(sources, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("TokenSources(%q) = %v, %v, expected %v", test.value, sources, err, test.expected)
+		}
+	}
+}
+
+func TestGetHostToken(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell function")
+	}
+
+	tests := []struct {
+		name           string
+		sources        string
+		host           string
+		env            map[string]string
+		keychain       string
+		hostsYml       string
+		helper         string
+		expectedToken  string
+		expectedSource string
+	}{
+		{
+			name:           "GHTokenBeforeGitHubToken",
+			env:            map[string]string{"GH_TOKEN": "gh-env", "GITHUB_TOKEN": "github-env"},
+			expectedToken:  "gh-env",
+			expectedSource: "GH_TOKEN",
+		},
+		{
+			name:           "PerHostVariable",
+			env:            map[string]string{"GH_TOKEN": "gh-env", "GITHUB_TOKEN_GITHUB_COM": "host-env"},
+			expectedToken:  "host-env",
+			expectedSource: "GITHUB_TOKEN_GITHUB_COM",
+		},
+		{
+			name:           "EnterpriseHost",
+			host:           "ghe.example.com",
+			env:            map[string]string{"GH_TOKEN": "gh-env", "GH_ENTERPRISE_TOKEN": "ghe-env"},
+			expectedToken:  "ghe-env",
+			expectedSource: "GH_ENTERPRISE_TOKEN",
+		},
+		{
+			name:           "KeychainFirstByDefault",
+			env:            map[string]string{"GITHUB_TOKEN": "github-env"},
+			keychain:       "stored",
+			expectedToken:  "stored",
+			expectedSource: "keychain (file)",
+		},
+		{
+			name:           "EnvBeforeKeychain",
+			sources:        "env,keychain",
+			env:            map[string]string{"GITHUB_TOKEN": "github-env"},
+			keychain:       "stored",
+			expectedToken:  "github-env",
+			expectedSource: "GITHUB_TOKEN",
+		},
+		{
+			name:           "GHHostsFile",
+			hostsYml:       "github.com:\n    user: octocat\n    oauth_token: gho_hosts\n    git_protocol: https\n",
+			expectedToken:  "gho_hosts",
+			expectedSource: "gh ",
+		},
+		{
+			name:           "GHHostsFileOtherHost",
+			sources:        "gh,credential",
+			hostsYml:       "ghe.example.com:\n    oauth_token: gho_other\n",
+			helper:         "!f() { echo username=octocat; echo password=cred; }; f",
+			expectedToken:  "cred",
+			expectedSource: "git credential fill",
+		},
+		{
+			name:           "CredentialBeforeGH",
+			sources:        "credential,gh",
+			hostsYml:       "github.com:\n    oauth_token: gho_hosts\n",
+			helper:         "!f() { echo username=octocat; echo password=cred; }; f",
+			expectedToken:  "cred",
+			expectedSource: "git credential fill",
+		},
+		{
+			name:           "CredentialWithoutHelper",
+			sources:        "credential,env",
+			env:            map[string]string{"GITHUB_TOKEN": "github-env"},
+			expectedToken:  "github-env",
+			expectedSource: "GITHUB_TOKEN",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			home := isolateSources(t)
+			t.Setenv("GIT_KEYCHAIN_SOURCES", test.sources)
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			if test.keychain != "" {
+				if err := StoreTokenInKeychain(test.keychain); err != nil {
+					t.Fatalf("failed to store token: %v", err)
+				}
+			}
+			if test.hostsYml != "" {
+				if err := os.MkdirAll(filepath.Join(home, "gh"), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(home, "gh", "hosts.yml"), []byte(test.hostsYml), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			gitconfig := ""
+			if test.helper != "" {
+				gitconfig = "[credential]\n\thelper = \"" + test.helper + "\"\n"
+			}
+			if err := os.WriteFile(filepath.Join(home, "gitconfig"), []byte(gitconfig), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			host := test.host
+			if host == "" {
+				host = DefaultHost
+			}
+			token, source, err := GetHostToken(host)
+			if err != nil {
+				t.Fatalf("GetHostToken(%q) failed: %v", host, err)
+			}
+			if token != test.expectedToken || !strings.HasPrefix(source, test.expectedSource) {
+				t.Errorf("GetHostToken(%q) = %q from %q, expected %q from %q", host, token, source, test.expectedToken, test.expectedSource)
+			}
+		})
+	}
+}
+
+func TestGetHostTokenNotFound(t *testing.T) {
+	isolateSources(t)
+	t.Setenv("GIT_KEYCHAIN_SOURCES", "")
+
+	_, _, err := GetHostToken(DefaultHost)
+	if err == nil || !strings.Contains(err.Error(), "GitHub token not found") {
+		t.Errorf("GetHostToken() error = %v, expected a not found error", err)
+	}
+}
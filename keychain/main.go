@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"os"
-	"runtime"
 	"strings"
 
 	keychain "github.com/jtamagnan/git-utils/keychain/lib"
@@ -15,13 +14,25 @@ func runE(cmd *cobra.Command, args []string) error {
 	fmt.Println("GitHub Token Keychain Setup for git-review")
 	fmt.Println()
 
-	if runtime.GOOS != "darwin" {
-		fmt.Println("This tool is only supported on macOS")
-		return fmt.Errorf("macOS required")
+	name, _ := cmd.Flags().GetString("backend")
+	if name == "" {
+		name = keychain.ConfiguredBackend()
 	}
+	backend, err := keychain.NewBackend(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using the %s token store.\n", backend.Name())
+	// Tell why an automatic choice fell back to the file on Linux
+	if (name == "" || name == keychain.BackendAuto) && backend.Name() == keychain.BackendFile {
+		if reason := keychain.SecretServiceUnavailable(); reason != "" {
+			fmt.Printf("The Secret Service is not used: %s.\n", reason)
+		}
+	}
+	fmt.Println()
 
 	// Check if token already exists
-	if keychain.HasExistingToken() {
+	if keychain.HasToken(backend) {
 		fmt.Println("GitHub token already found in keychain.")
 
 		// Prompt user if they want to keep it or replace it
@@ -69,7 +80,7 @@ func runE(cmd *cobra.Command, args []string) error {
 	}
 
 	// Store in keychain
-	err = keychain.StoreTokenInKeychain(token)
+	err = backend.Store(token)
 	if err != nil {
 		return fmt.Errorf("failed to store token in keychain: %v", err)
 	}
@@ -88,16 +99,22 @@ func runE(cmd *cobra.Command, args []string) error {
 func generateCommand() *cobra.Command {
 	var rootCmd = &cobra.Command{
 		Use:   "git-keychain",
-		Short: "Manage GitHub tokens securely in macOS keychain",
-		Long: `Store and manage GitHub Personal Access Tokens securely in the macOS keychain.
+		Short: "Manage GitHub tokens securely in the system keychain",
+		Long: `Store and manage GitHub Personal Access Tokens securely in the system keychain.
+
+This tool allows you to securely store your GitHub token in the macOS keychain,
+the Secret Service on Linux, or a passphrase-encrypted file instead of using
+environment variables. The stored token will be automatically used by
+git-review and other git-utils tools.
 
-This tool allows you to securely store your GitHub token in the macOS keychain
-instead of using environment variables. The stored token will be automatically
-used by git-review and other git-utils tools.`,
+The token store is chosen automatically, or set with GIT_KEYCHAIN_BACKEND or
+git config keychain.backend.`,
 		RunE:         runE,
 		SilenceUsage: true,
 	}
 
+	rootCmd.Flags().String("backend", "", "Token store to use: "+strings.Join(keychain.Backends(), ", "))
+
 	return rootCmd
 }
 
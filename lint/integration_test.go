@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -319,3 +322,66 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// TestWriteReportAfterCachedRun tests that a structured report lists the
+// hooks even when the same tree already passed and is in the cache
+func TestWriteReportAfterCachedRun(t *testing.T) {
+	testRepo := git.NewTestRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.AddCommit("test.txt", "test content\n", "Initial commit")
+	testRepo.AddRemote("origin", "https://github.com/example/repo.git")
+	testRepo.CreateRemoteTrackingBranch("origin", "main")
+	testRepo.SetUpstream("origin", "main")
+	testRepo.AddCommit("other.txt", "other content\n", "Add other")
+
+	testRepo.InDir(func() {
+		if err := lint.Lint(lint.ParsedArgs{Output: io.Discard}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := writeReport(&buf, lint.ParsedArgs{}, "junit"); err != nil {
+			t.Fatalf("writeReport failed: %v", err)
+		}
+		if strings.Contains(buf.String(), `tests="0"`) || !strings.Contains(buf.String(), "final-newline") {
+			t.Errorf("Expected the report to list the hooks, got:\n%s", buf.String())
+		}
+	})
+}
+
+// TestLoggingFlags tests that --verbose and --log-file enable the debug logs
+func TestLoggingFlags(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	logFile := filepath.Join(t.TempDir(), "git-lint.log")
+	cmd := generateCommand()
+	if err := cmd.ParseFlags([]string{"--verbose", "--log-file", logFile}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := setupLogging(cmd); err != nil {
+		t.Fatalf("setupLogging failed: %v", err)
+	}
+	slog.Debug("git", "args", "rev-parse HEAD", "token", "ghp_secret")
+	closeLog()
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "rev-parse HEAD") {
+		t.Errorf("Expected the debug log in %s, got %q", logFile, content)
+	}
+	if strings.Contains(string(content), "ghp_secret") {
+		t.Errorf("Expected the token to be redacted, got %q", content)
+	}
+
+	cmd = generateCommand()
+	if err := cmd.ParseFlags([]string{"--log-level", "loud"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := setupLogging(cmd); err == nil {
+		t.Error("Expected an invalid --log-level to be rejected")
+	}
+}
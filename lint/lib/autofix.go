@@ -0,0 +1,316 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jtamagnan/git-utils/git"
+)
+
+// hunk is a change made by a lint hook, from `git diff -U0`
+type hunk struct {
+	oldStart int      // first changed line, or the line inserted after if oldCount is 0
+	oldCount int      // number of lines replaced
+	newLines []string // replacement lines, with their line endings
+}
+
+// fileFix holds the changes a lint hook made to one file
+type fileFix struct {
+	path  string
+	hunks []hunk
+	whole bool // binary or otherwise not line-based, the whole file is taken as is
+}
+
+// hunkHeaderPrefix starts every hunk header, e.g. "@@ -12,3 +12,4 @@"
+const hunkHeaderPrefix = "@@ -"
+
+// parseFixes parses the output of `git diff -U0` into the changes per file
+func parseFixes(diff string) []fileFix {
+	var fixes []fileFix
+	var current *fileFix
+	var currentHunk *hunk
+	lastAdded := false
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			fixes = append(fixes, fileFix{path: headerPath(line)})
+			current = &fixes[len(fixes)-1]
+			currentHunk = nil
+		case current == nil:
+			continue
+		case currentHunk == nil && strings.HasPrefix(line, "+++ b/"):
+			current.path = strings.TrimSuffix(strings.TrimPrefix(line, "+++ b/"), "\n")
+		case currentHunk == nil && (strings.HasPrefix(line, "Binary files ") || strings.HasPrefix(line, "old mode ") ||
+			strings.HasPrefix(line, "deleted file mode ") || strings.HasPrefix(line, "new file mode ")):
+			current.whole = true
+		case strings.HasPrefix(line, hunkHeaderPrefix):
+			h, ok := parseHunkHeader(line)
+			if !ok {
+				// Not something that can be split up, take the whole file
+				current.whole = true
+				continue
+			}
+			current.hunks = append(current.hunks, h)
+			currentHunk = &current.hunks[len(current.hunks)-1]
+		case currentHunk == nil:
+			continue
+		case strings.HasPrefix(line, "+"):
+			currentHunk.newLines = append(currentHunk.newLines, strings.TrimPrefix(line, "+"))
+			lastAdded = true
+			continue
+		case strings.HasPrefix(line, `\ No newline at end of file`):
+			// Removed lines are taken from HEAD, only added ones need fixing up
+			if n := len(currentHunk.newLines); lastAdded && n > 0 {
+				currentHunk.newLines[n-1] = strings.TrimSuffix(currentHunk.newLines[n-1], "\n")
+			}
+		}
+		lastAdded = false
+	}
+	return fixes
+}
+
+// headerPath returns the path of "diff --git a/<path> b/<path>"
+func headerPath(line string) string {
+	paths := strings.TrimPrefix(strings.TrimSuffix(line, "\n"), "diff --git a/")
+	return paths[:max(len(paths)-3, 0)/2]
+}
+
+// parseHunkHeader parses "@@ -start[,count] +start[,count] @@"
+func parseHunkHeader(line string) (hunk, bool) {
+	oldRange, _, ok := strings.Cut(strings.TrimPrefix(line, hunkHeaderPrefix), " ")
+	if !ok {
+		return hunk{}, false
+	}
+	startText, countText, hasCount := strings.Cut(oldRange, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return hunk{}, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return hunk{}, false
+		}
+	}
+	return hunk{oldStart: start, oldCount: count}, true
+}
+
+// applyHunks applies hunks, which must not overlap, to the lines of a file
+func applyHunks(lines []string, hunks []hunk) []string {
+	sorted := append([]hunk(nil), hunks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].oldStart > sorted[j].oldStart })
+
+	result := append([]string(nil), lines...)
+	for _, h := range sorted {
+		start := h.oldStart
+		if h.oldCount > 0 {
+			start--
+		}
+		tail := append([]string(nil), result[start+h.oldCount:]...)
+		result = append(append(result[:start], h.newLines...), tail...)
+	}
+	return result
+}
+
+// splitLines splits a hunk that replaces lines one for one, as formatters
+// usually do, into a hunk per line so that each line can be blamed on its own
+func splitLines(h hunk) []hunk {
+	if h.oldCount <= 1 || h.oldCount != len(h.newLines) {
+		return []hunk{h}
+	}
+	hunks := make([]hunk, h.oldCount)
+	for i := range hunks {
+		hunks[i] = hunk{oldStart: h.oldStart + i, oldCount: 1, newLines: h.newLines[i : i+1]}
+	}
+	return hunks
+}
+
+// blameCommits returns the commits that last touched the lines a hunk
+// replaces, or the line it inserts after
+func blameCommits(repo *git.Repository, path string, h hunk) []string {
+	first, last := h.oldStart, h.oldStart+h.oldCount-1
+	if h.oldCount == 0 {
+		first, last = max(h.oldStart, 1), max(h.oldStart, 1)
+	}
+	out, err := repo.GitExec("blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", first, last), "HEAD", "--", path)
+	if err != nil {
+		// e.g. an insertion into an empty file, there is nothing to blame
+		return nil
+	}
+
+	var commits []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 && isHex(fields[0]) {
+			commits = append(commits, fields[0])
+		}
+	}
+	return commits
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// fixupTarget picks the stack commit a hunk is folded into: the newest stack
+// commit that last touched its lines, otherwise the newest stack commit that
+// touched the file, otherwise the tip of the stack. stack is newest first.
+func fixupTarget(repo *git.Repository, fromRef string, stack []string, path string, h hunk) string {
+	blamed := blameCommits(repo, path, h)
+	for _, commit := range stack {
+		for _, b := range blamed {
+			if b == commit {
+				return commit
+			}
+		}
+	}
+
+	lastTouched, err := repo.GitExec("log", "-1", "--format=%H", fromRef+"..HEAD", "--", path)
+	if err == nil && lastTouched != "" {
+		return lastTouched
+	}
+	return stack[0]
+}
+
+// hasUncommittedChanges reports whether the index or the working tree differ
+// from HEAD
+func hasUncommittedChanges(repo *git.Repository) bool {
+	_, err := repo.GitExec("diff", "--quiet", "HEAD")
+	return err != nil
+}
+
+// autofix folds the changes lint hooks made to the working tree into the
+// commits between fromRef and HEAD. Each hunk becomes part of a fixup commit
+// for the commit that last touched its lines, and the fixups are then
+// autosquashed. Squashing keeps the target's message, so "PR URL:" stamps
+// are preserved. It returns false if the hooks changed nothing.
+func autofix(repo *git.Repository, root, fromRef string) (bool, error) {
+	diff, err := gitOutput(root, nil, "", "diff", "-U0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/")
+	if err != nil || diff == "" {
+		return false, err
+	}
+	fixes := parseFixes(diff)
+
+	stackOut, err := repo.GitExec("rev-list", fromRef+"..HEAD")
+	if err != nil {
+		return false, err
+	}
+	if stackOut == "" {
+		return false, fmt.Errorf("no commits to fold the lint fixes into")
+	}
+	stack := strings.Split(stackOut, "\n")
+
+	// Assign every hunk to its commit
+	assigned := map[string]map[string][]hunk{} // commit -> path -> hunks
+	wholeFiles := map[string][]string{}        // commit -> paths taken as is
+	for _, fix := range fixes {
+		if fix.whole {
+			wholeFiles[stack[0]] = append(wholeFiles[stack[0]], fix.path)
+			continue
+		}
+		var hunks []hunk
+		for _, h := range fix.hunks {
+			hunks = append(hunks, splitLines(h)...)
+		}
+		for _, h := range hunks {
+			target := fixupTarget(repo, fromRef, stack, fix.path, h)
+			if assigned[target] == nil {
+				assigned[target] = map[string][]hunk{}
+			}
+			assigned[target][fix.path] = append(assigned[target][fix.path], h)
+		}
+	}
+
+	// Create the fixup commits, oldest target first. Each one stages the
+	// hunks applied so far on top of the file as committed in HEAD.
+	applied := map[string][]hunk{}
+	original := map[string][]string{}
+	for i := len(stack) - 1; i >= 0; i-- {
+		target := stack[i]
+		if assigned[target] == nil && wholeFiles[target] == nil {
+			continue
+		}
+		for path, hunks := range assigned[target] {
+			if _, ok := original[path]; !ok {
+				content, err := gitOutput(root, nil, "", "show", "HEAD:"+path)
+				if err != nil {
+					return false, err
+				}
+				original[path] = strings.SplitAfter(content, "\n")
+			}
+			applied[path] = append(applied[path], hunks...)
+			content := strings.Join(applyHunks(original[path], applied[path]), "")
+			if err := stageContent(repo, root, path, content); err != nil {
+				return false, err
+			}
+		}
+		for _, path := range wholeFiles[target] {
+			if _, err := repo.GitExec("add", "--all", "--", path); err != nil {
+				return false, err
+			}
+		}
+		if _, err := repo.GitExec("commit", "--quiet", "--no-verify", "-m", "fixup! "+target); err != nil {
+			return false, fmt.Errorf("failed to commit lint fixes for %s: %v", target[:8], err)
+		}
+	}
+
+	if hasUncommittedChanges(repo) {
+		return false, fmt.Errorf("failed to fold every lint fix into a commit, the rest is left in the working tree")
+	}
+
+	// Rebase onto the merge base so that only the fixups are squashed and
+	// the stack does not move onto new upstream commits
+	base, err := repo.GitExec("merge-base", fromRef, "HEAD")
+	if err != nil {
+		return false, err
+	}
+	env := append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
+	if _, err := gitOutput(root, env, "", "rebase", "--quiet", "--interactive", "--autosquash", base); err != nil {
+		if _, abortErr := repo.GitExec("rebase", "--abort"); abortErr != nil {
+			return false, fmt.Errorf("failed to squash lint fixes: %v (and failed to abort the rebase: %v)", err, abortErr)
+		}
+		return false, fmt.Errorf("failed to squash lint fixes, the fixup commits are left on the branch for `git rebase -i --autosquash %s`: %v", base, err)
+	}
+	return true, nil
+}
+
+// stageContent writes content to the index as the new version of path
+func stageContent(repo *git.Repository, root, path, content string) error {
+	blob, err := gitOutput(root, nil, content, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	entry, err := repo.GitExec("ls-files", "--stage", "--", path)
+	if err != nil || entry == "" {
+		return fmt.Errorf("failed to read the index entry of %s: %v", path, err)
+	}
+	mode, _, _ := strings.Cut(entry, " ")
+	_, err = repo.GitExec("update-index", "--cacheinfo", mode+","+strings.TrimSpace(blob)+","+path)
+	return err
+}
+
+// gitOutput runs git in root with the given environment (nil for the
+// current one) and stdin, and returns its untrimmed stdout
+func gitOutput(root string, env []string, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running git command: `%s` \n %s", cmd.String(), stderr.String())
+	}
+	return string(out), nil
+}
@@ -0,0 +1,140 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAndApplyFixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		diff     string
+		expected string
+	}{
+		{
+			name:     "TrailingWhitespace",
+			original: "one  \ntwo\nthree \n",
+			diff: "diff --git a/f b/f\n--- a/f\n+++ b/f\n" +
+				"@@ -1 +1 @@\n-one  \n+one\n" +
+				"@@ -3 +3 @@\n-three \n+three\n",
+			expected: "one\ntwo\nthree\n",
+		},
+		{
+			name:     "MissingFinalNewline",
+			original: "one\ntwo",
+			diff: "diff --git a/f b/f\n--- a/f\n+++ b/f\n" +
+				"@@ -2 +2 @@\n-two\n\\ No newline at end of file\n+two\n",
+			expected: "one\ntwo\n",
+		},
+		{
+			name:     "InsertAndDelete",
+			original: "a\nb\nc\n",
+			diff: "diff --git a/f b/f\n--- a/f\n+++ b/f\n" +
+				"@@ -0,0 +1 @@\n+header\n" +
+				"@@ -2 +2,0 @@\n-b\n",
+			expected: "header\na\nc\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixes := parseFixes(tt.diff)
+			if len(fixes) != 1 || fixes[0].path != "f" || fixes[0].whole {
+				t.Fatalf("Expected one line-based fix for f, got %+v", fixes)
+			}
+			lines := strings.SplitAfter(tt.original, "\n")
+			if result := strings.Join(applyHunks(lines, fixes[0].hunks), ""); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseFixesBinary(t *testing.T) {
+	diff := "diff --git a/img.png b/img.png\nindex 1..2 100644\nBinary files a/img.png and b/img.png differ\n"
+	fixes := parseFixes(diff)
+	if !reflect.DeepEqual(fixes, []fileFix{{path: "img.png", whole: true}}) {
+		t.Errorf("Expected the binary file to be taken whole, got %+v", fixes)
+	}
+}
+
+// stripWhitespaceCommand strips trailing spaces from a.txt and b.txt and
+// fails if there were any, like a pre-commit fixer hook
+const stripWhitespaceCommand = `status=0
+for f in a.txt b.txt; do
+  [ -e "$f" ] || continue
+  if grep -q ' $' "$f"; then
+    sed 's/ *$//' "$f" > "$f.tmp" && mv "$f.tmp" "$f"
+    status=1
+  fi
+done
+exit $status`
+
+// TestLintAutofix tests that fixes land in the commits that introduced the
+// offending lines and that PR URL stamps survive the squash
+func TestLintAutofix(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.GitExec("rm", "-q", "--cached", "b.txt")
+	testRepo.GitExec("reset", "-q", "--hard", "origin/main")
+
+	testRepo.AddCommit("a.txt", "one \ntwo\nthree\n", "Add a")
+	testRepo.CreateFile("a.txt", "one \ntwo\nTHREE \n")
+	testRepo.CreateFile("b.txt", "x \n")
+	testRepo.GitExec("add", "a.txt", "b.txt")
+	testRepo.GitExec("commit", "-q", "-m", "Add b\n\nPR URL: https://github.com/example/repo/pull/2")
+	testRepo.GitExec("config", "lint.command", stripWhitespaceCommand)
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{}); err == nil {
+			t.Fatal("Expected lint to fail without autofix")
+		}
+		testRepo.GitExec("checkout", "-q", "--", ".")
+
+		if err := Lint(ParsedArgs{Autofix: true}); err != nil {
+			t.Fatalf("Expected autofix to fix the branch, got: %v", err)
+		}
+	})
+
+	if log := testRepo.GitExec("log", "--format=%s", "origin/main..HEAD"); log != "Add b\nAdd a" {
+		t.Errorf("Expected the fixups to be squashed, got log:\n%s", log)
+	}
+	files := map[string]string{
+		"HEAD~1:a.txt": "one\ntwo\nthree",
+		"HEAD:a.txt":   "one\ntwo\nTHREE",
+		"HEAD:b.txt":   "x",
+	}
+	// GitExec trims the output, so the final newlines are not compared
+	for object, expected := range files {
+		if content := testRepo.GitExec("show", object); content != expected {
+			t.Errorf("Expected %s to be %q, got %q", object, expected, content)
+		}
+	}
+	if message := testRepo.GitExec("log", "-1", "--format=%B"); !strings.Contains(message, "PR URL: https://github.com/example/repo/pull/2") {
+		t.Errorf("Expected the PR URL stamp to be kept, got:\n%s", message)
+	}
+	if status := testRepo.GitExec("status", "--porcelain"); status != "" {
+		t.Errorf("Expected a clean working tree, got:\n%s", status)
+	}
+}
+
+// TestLintAutofixDirtyTree tests that autofix leaves uncommitted work alone
+func TestLintAutofixDirtyTree(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.CreateFile("b.txt", "x \n")
+	testRepo.GitExec("add", "b.txt")
+	testRepo.GitExec("config", "lint.command", stripWhitespaceCommand)
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{Autofix: true}); err == nil {
+			t.Error("Expected lint to fail when autofix is skipped")
+		}
+	})
+
+	if log := testRepo.GitExec("log", "--format=%s", "origin/main..HEAD"); log != "Add a" {
+		t.Errorf("Expected no commits to be made, got log:\n%s", log)
+	}
+}
@@ -0,0 +1,458 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltinConfig configures the built-in checks, from the "builtin" part of
+// the lint section of .git-review.yaml
+type BuiltinConfig struct {
+	Enabled       *bool    `yaml:"enabled"`          // fall back to the built-in checks, true if unset
+	Enable        []string `yaml:"enable"`           // opt-in checks to run, e.g. debug-statements
+	Skip          []string `yaml:"skip"`             // checks not to run
+	Exclude       []string `yaml:"exclude"`          // paths, globs or directories ending in "/" not to check
+	MaxFileSizeKB int64    `yaml:"max-file-size-kb"` // limit of large-files, defaultMaxFileSizeKB if unset
+	DebugPatterns []string `yaml:"debug-patterns"`   // extra regexps for debug-statements
+}
+
+// enabled reports whether the built-in checks are used when no other runner
+// applies
+func (c BuiltinConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// defaultMaxFileSizeKB is the default limit of the large-files check
+const defaultMaxFileSizeKB = 500
+
+// builtinCheck is one check of the built-in runner
+type builtinCheck struct {
+	id          string
+	description string
+	// binary is set for checks that also apply to binary files
+	binary bool
+	// optIn is set for checks that only run when named or listed in
+	// lint.builtin.enable, since they flag code that is fine in some projects
+	optIn bool
+	// applies reports whether the check looks at path, nil for every file
+	applies func(path string) bool
+	// check returns the problems in content, as "line: message" or
+	// "message", and the fixed content if it can be fixed safely. Messages
+	// of gofmt and parsers start with "line:column: ".
+	check func(r builtinRunner, path string, content []byte) (problems []string, fixed []byte)
+}
+
+// builtinChecks are the built-in checks, in the order they run
+var builtinChecks = []builtinCheck{
+	{
+		id:          "conflict-markers",
+		description: "Leftover merge conflict markers",
+		check:       checkConflictMarkers,
+	},
+	{
+		id:          "trailing-whitespace",
+		description: "Trailing spaces and tabs",
+		check:       checkTrailingWhitespace,
+	},
+	{
+		id:          "final-newline",
+		description: "Missing newline at the end of the file",
+		check:       checkFinalNewline,
+	},
+	{
+		id:          "large-files",
+		description: "Files over max-file-size-kb",
+		binary:      true,
+		check:       checkLargeFile,
+	},
+	{
+		id:          "debug-statements",
+		description: "Leftover debugger calls and debug prints",
+		optIn:       true,
+		applies:     hasExtension(".go", ".py", ".rb", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".vue", ".svelte"),
+		check:       checkDebugStatements,
+	},
+	{
+		id:          "gofmt",
+		description: "Go files not formatted with gofmt",
+		applies:     hasExtension(".go"),
+		check:       checkGofmt,
+	},
+	{
+		id:          "yaml",
+		description: "Invalid YAML",
+		applies:     hasExtension(".yaml", ".yml"),
+		check:       checkYAML,
+	},
+	{
+		id:          "json",
+		description: "Invalid JSON",
+		applies:     hasExtension(".json"),
+		check:       checkJSON,
+	},
+}
+
+// BuiltinCheckIDs returns the ids of the built-in checks
+func BuiltinCheckIDs() []string {
+	ids := make([]string, len(builtinChecks))
+	for i, check := range builtinChecks {
+		ids[i] = check.id
+	}
+	return ids
+}
+
+// hasExtension returns a filter for files with one of extensions
+func hasExtension(extensions ...string) func(string) bool {
+	return func(path string) bool {
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range extensions {
+			if ext == e {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var (
+	conflictStartPattern = regexp.MustCompile(`^<{7}(?: |$)`)
+	conflictEndPattern   = regexp.MustCompile(`^>{7}(?: |$)`)
+	conflictMidPattern   = regexp.MustCompile(`^(?:={7}|\|{7})(?: |$)`)
+	// debugPatterns match leftover debugging in common languages
+	debugPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:import\s+i?pdb\b|from\s+i?pdb\s+import\b)`),
+		regexp.MustCompile(`\b(?:i?pdb\.set_trace|breakpoint)\(\)`),
+		regexp.MustCompile(`\bbinding\.(?:pry|irb)\b`),
+		regexp.MustCompile(`^\s*byebug\s*$`),
+		regexp.MustCompile(`^\s*debugger;?\s*$`),
+		regexp.MustCompile(`\bconsole\.(?:log|debug)\(`),
+		regexp.MustCompile(`\bruntime\.Breakpoint\(\)`),
+		regexp.MustCompile(`^\s*println\(`),
+		regexp.MustCompile(`\bspew\.(?:Dump|Printf)\(`),
+	}
+)
+
+// lines splits content into lines, without their line endings
+func lines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+func checkConflictMarkers(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	var problems []string
+	inConflict := false
+	for i, line := range lines(content) {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case conflictStartPattern.MatchString(line):
+			inConflict = true
+		case conflictEndPattern.MatchString(line):
+			inConflict = false
+		case inConflict && conflictMidPattern.MatchString(line):
+		default:
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%d: conflict marker %q", i+1, line))
+	}
+	return problems, nil
+}
+
+func checkTrailingWhitespace(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	var problems []string
+	fixed := strings.SplitAfter(string(content), "\n")
+	for i, line := range fixed {
+		body := strings.TrimSuffix(line, "\n")
+		ending := line[len(body):]
+		if strings.HasSuffix(body, "\r") {
+			body, ending = strings.TrimSuffix(body, "\r"), "\r"+ending
+		}
+		trimmed := strings.TrimRight(body, " \t")
+		if trimmed != body {
+			problems = append(problems, fmt.Sprintf("%d: trailing whitespace", i+1))
+			fixed[i] = trimmed + ending
+		}
+	}
+	if problems == nil {
+		return nil, nil
+	}
+	return problems, []byte(strings.Join(fixed, ""))
+}
+
+func checkFinalNewline(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	if len(content) == 0 || bytes.HasSuffix(content, []byte("\n")) {
+		return nil, nil
+	}
+	return []string{"no newline at end of file"}, append(content, '\n')
+}
+
+func checkLargeFile(r builtinRunner, _ string, content []byte) ([]string, []byte) {
+	limit := r.config.MaxFileSizeKB
+	if limit <= 0 {
+		limit = defaultMaxFileSizeKB
+	}
+	if size := int64(len(content)) / 1024; size > limit {
+		return []string{fmt.Sprintf("file is %d KB, over the limit of %d KB", size, limit)}, nil
+	}
+	return nil, nil
+}
+
+func checkDebugStatements(r builtinRunner, _ string, content []byte) ([]string, []byte) {
+	var problems []string
+	for i, line := range lines(content) {
+		for _, pattern := range r.debugPatterns {
+			if pattern.MatchString(line) {
+				problems = append(problems, fmt.Sprintf("%d: debug statement %q", i+1, strings.TrimSpace(line)))
+				break
+			}
+		}
+	}
+	return problems, nil
+}
+
+func checkGofmt(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	formatted, err := format.Source(content)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	if bytes.Equal(formatted, content) {
+		return nil, nil
+	}
+	return []string{"not formatted with gofmt"}, formatted
+}
+
+func checkYAML(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return []string{err.Error()}, nil
+		}
+	}
+}
+
+func checkJSON(_ builtinRunner, _ string, content []byte) ([]string, []byte) {
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return []string{err.Error()}, nil
+	}
+	return nil, nil
+}
+
+// builtinRunner runs the built-in checks on the changed files, or on every
+// tracked file with --all. Safe fixes are written with --fix.
+type builtinRunner struct {
+	config        BuiltinConfig
+	debugPatterns []*regexp.Regexp
+}
+
+// newBuiltinRunner returns the built-in runner for config
+func newBuiltinRunner(config BuiltinConfig) (builtinRunner, error) {
+	r := builtinRunner{config: config, debugPatterns: debugPatterns}
+	for _, pattern := range config.DebugPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return r, fmt.Errorf("invalid lint.builtin.debug-patterns %q: %v", pattern, err)
+		}
+		r.debugPatterns = append(r.debugPatterns, re)
+	}
+	for _, id := range config.Enable {
+		if !isBuiltinCheck(id) {
+			return r, fmt.Errorf("unknown check %q in lint.builtin.enable: expected one of %s", id, strings.Join(BuiltinCheckIDs(), ", "))
+		}
+	}
+	for _, id := range config.Skip {
+		if !isBuiltinCheck(id) {
+			return r, fmt.Errorf("unknown check %q in lint.builtin.skip: expected one of %s", id, strings.Join(BuiltinCheckIDs(), ", "))
+		}
+	}
+	return r, nil
+}
+
+// isBuiltinCheck reports whether id names a built-in check
+func isBuiltinCheck(id string) bool {
+	for _, check := range builtinChecks {
+		if check.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (builtinRunner) Name() string {
+	return "builtin"
+}
+
+// excluded reports whether file matches one of the exclude patterns
+func (r builtinRunner) excluded(file string) bool {
+	for _, pattern := range r.config.Exclude {
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(file, pattern) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(file)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// selected returns the checks to run: the named ones, otherwise every check
+// that is not opt-in or enabled by the config, and not skipped by it. Checks
+// in skip never run.
+func (r builtinRunner) selected(checks, skip []string) []builtinCheck {
+	var selected []builtinCheck
+	for _, check := range builtinChecks {
+		if slices.Contains(skip, check.id) {
+			continue
+		}
+		if len(checks) > 0 {
+			if slices.Contains(checks, check.id) {
+				selected = append(selected, check)
+			}
+		} else if (!check.optIn || slices.Contains(r.config.Enable, check.id)) && !slices.Contains(r.config.Skip, check.id) {
+			selected = append(selected, check)
+		}
+	}
+	return selected
+}
+
+func (r builtinRunner) Run(target Target, checks []string) (*Report, error) {
+	files := target.Files
+	if target.AllFiles {
+		out, err := gitOutput(target.Root, nil, "", "ls-files", "-z")
+		if err != nil {
+			return nil, err
+		}
+		files = strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	}
+
+	// Read every file once, checks run on the fixed content of the checks
+	// before them
+	contents := map[string][]byte{}
+	var paths []string
+	for _, file := range files {
+		if file == "" || r.excluded(file) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(target.Root, file))
+		if err != nil {
+			// Deleted or not a regular file, e.g. a submodule
+			continue
+		}
+		contents[file] = content
+		paths = append(paths, file)
+	}
+
+	report := &Report{Runner: r.Name()}
+	fixedFiles := map[string]bool{}
+	for _, check := range r.selected(checks, target.Skip) {
+		if err := context.Cause(target.context()); err != nil {
+			return report, err
+		}
+		start := time.Now()
+		hook := HookResult{ID: check.id, Name: check.description, Status: StatusSkipped}
+		var output []string
+		for _, file := range paths {
+			if check.applies != nil && !check.applies(file) {
+				continue
+			}
+			content := contents[file]
+			if !check.binary && isBinary(content) {
+				continue
+			}
+			if hook.Status == StatusSkipped {
+				hook.Status = StatusPassed
+			}
+
+			problems, fixed := check.check(r, file, content)
+			if len(problems) == 0 {
+				continue
+			}
+			hook.Status = StatusFailed
+			hook.Files = append(hook.Files, file)
+			for _, problem := range problems {
+				if problem != "" && problem[0] >= '0' && problem[0] <= '9' {
+					output = append(output, file+":"+problem)
+				} else {
+					output = append(output, file+": "+problem)
+				}
+			}
+			if target.Fix && fixed != nil {
+				contents[file] = fixed
+				fixedFiles[file] = true
+				hook.Modified = true
+			}
+		}
+		hook.Duration = time.Since(start)
+		hook.Output = strings.Join(output, "\n")
+		if target.Stream {
+			printBuiltinResult(target.output(), hook)
+		}
+		report.Hooks = append(report.Hooks, hook)
+	}
+
+	for _, file := range paths {
+		if !fixedFiles[file] {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(target.Root, file))
+		if err != nil {
+			return report, err
+		}
+		if err := os.WriteFile(filepath.Join(target.Root, file), contents[file], info.Mode().Perm()); err != nil {
+			return report, fmt.Errorf("failed to fix %s: %v", file, err)
+		}
+	}
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, &FailedError{Hooks: failed}
+	}
+	return report, nil
+}
+
+// isBinary reports whether content looks binary, the way git decides it
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// printBuiltinResult prints a line per check like pre-commit, followed by
+// the problems found
+func printBuiltinResult(w io.Writer, hook HookResult) {
+	status := map[Status]string{StatusPassed: "Passed", StatusFailed: "Failed", StatusSkipped: "(no files to check)Skipped"}[hook.Status]
+	dots := max(3, 79-len(hook.Name)-len(status))
+	fmt.Fprintf(w, "%s%s%s\n", hook.Name, strings.Repeat(".", dots), status)
+	if hook.Status != StatusFailed {
+		return
+	}
+	fmt.Fprintf(w, "- hook id: %s\n", hook.ID)
+	if hook.Modified {
+		fmt.Fprintln(w, "- files were modified by this hook")
+	}
+	fmt.Fprintf(w, "\n%s\n\n", hook.Output)
+}
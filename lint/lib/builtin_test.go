@@ -0,0 +1,190 @@
+package lint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinChecks(t *testing.T) {
+	runner, err := newBuiltinRunner(BuiltinConfig{MaxFileSizeKB: 1, DebugPatterns: []string{`\bdd\(`}})
+	if err != nil {
+		t.Fatalf("newBuiltinRunner failed: %v", err)
+	}
+
+	tests := []struct {
+		check    string
+		path     string
+		content  string
+		problems []string
+		fixed    string // empty if the check offers no fix
+	}{
+		{check: "conflict-markers", path: "a.txt", content: "a\n=======\nb\n"},
+		{
+			check:    "conflict-markers",
+			path:     "a.txt",
+			content:  "<<<<<<< HEAD\na\n=======\nb\n>>>>>>> topic\n",
+			problems: []string{`1: conflict marker "<<<<<<< HEAD"`, `3: conflict marker "======="`, `5: conflict marker ">>>>>>> topic"`},
+		},
+		{check: "trailing-whitespace", path: "a.txt", content: "a\nb\n"},
+		{
+			check:    "trailing-whitespace",
+			path:     "a.txt",
+			content:  "a \nb\t\r\nc",
+			problems: []string{"1: trailing whitespace", "2: trailing whitespace"},
+			fixed:    "a\nb\r\nc",
+		},
+		{check: "final-newline", path: "a.txt", content: ""},
+		{check: "final-newline", path: "a.txt", content: "a", problems: []string{"no newline at end of file"}, fixed: "a\n"},
+		{check: "large-files", path: "a.bin", content: strings.Repeat("x", 1024)},
+		{check: "large-files", path: "a.bin", content: strings.Repeat("x", 2048), problems: []string{"file is 2 KB, over the limit of 1 KB"}},
+		{
+			check:    "debug-statements",
+			path:     "a.py",
+			content:  "import pdb\nx = 1\nbreakpoint()\ndd(x)\n",
+			problems: []string{`1: debug statement "import pdb"`, `3: debug statement "breakpoint()"`, `4: debug statement "dd(x)"`},
+		},
+		{check: "gofmt", path: "a.go", content: "package a\n"},
+		{check: "gofmt", path: "a.go", content: "package a\nvar  x=1\n", problems: []string{"not formatted with gofmt"}, fixed: "package a\n\nvar x = 1\n"},
+		{check: "gofmt", path: "a.go", content: "package a\nfunc {\n", problems: []string{"2:6: expected 'IDENT', found '{'"}},
+		{check: "yaml", path: "a.yaml", content: "a: 1\n---\nb: [2]\n"},
+		{check: "yaml", path: "a.yaml", content: "a: [1\n", problems: []string{"yaml: line 1: did not find expected ',' or ']'"}},
+		{check: "json", path: "a.json", content: `{"a": [1]}`},
+		{check: "json", path: "a.json", content: `{"a": }`, problems: []string{"invalid character '}' looking for beginning of value"}},
+	}
+
+	for _, tt := range tests {
+		var check builtinCheck
+		for _, c := range builtinChecks {
+			if c.id == tt.check {
+				check = c
+			}
+		}
+		if check.applies != nil && !check.applies(tt.path) {
+			t.Errorf("%s: expected the check to apply to %s", tt.check, tt.path)
+			continue
+		}
+
+		problems, fixed := check.check(runner, tt.path, []byte(tt.content))
+		if !reflect.DeepEqual(problems, tt.problems) {
+			t.Errorf("%s on %q: expected problems %q, got %q", tt.check, tt.content, tt.problems, problems)
+		}
+		if string(fixed) != tt.fixed {
+			t.Errorf("%s on %q: expected fix %q, got %q", tt.check, tt.content, tt.fixed, string(fixed))
+		}
+	}
+}
+
+// TestBuiltinRunner tests that the built-in runner checks the target files,
+// honours the repo config and only writes fixes with Fix
+func TestBuiltinRunner(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\nfunc main() {  }\n",
+		"notes.txt":            "done \n",
+		"vendor/lib/lib.go":    "package lib\nvar  x=1\n",
+		"README.md":            "console.log(1)\n",
+		"config/settings.yaml": "a: [1\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	runner, err := newBuiltinRunner(BuiltinConfig{Exclude: []string{"vendor/"}, Skip: []string{"yaml"}})
+	if err != nil {
+		t.Fatalf("newBuiltinRunner failed: %v", err)
+	}
+	target := Target{Root: root, Files: []string{"main.go", "notes.txt", "vendor/lib/lib.go", "README.md", "config/settings.yaml"}}
+
+	report, err := runner.Run(target, nil)
+	var failedErr *FailedError
+	if !errors.As(err, &failedErr) || !reflect.DeepEqual(failedErr.Hooks, []string{"trailing-whitespace", "gofmt"}) {
+		t.Fatalf("Expected trailing-whitespace and gofmt to fail, got %v", err)
+	}
+	for _, hook := range report.Hooks {
+		if hook.ID == "yaml" {
+			t.Errorf("Expected the skipped yaml check not to run")
+		}
+		if hook.ID == "gofmt" && !reflect.DeepEqual(hook.Files, []string{"main.go"}) {
+			t.Errorf("Expected gofmt to only report main.go, got %v", hook.Files)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "main.go")); string(content) != files["main.go"] {
+		t.Errorf("Expected main.go to be left alone without Fix, got %q", content)
+	}
+
+	target.Fix = true
+	if _, err := runner.Run(target, []string{"gofmt", "trailing-whitespace"}); err == nil {
+		t.Errorf("Expected the run that fixes files to fail")
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "main.go")); string(content) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("Expected main.go to be formatted, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "notes.txt")); string(content) != "done\n" {
+		t.Errorf("Expected notes.txt to be fixed, got %q", content)
+	}
+	if _, err := runner.Run(target, nil); err != nil {
+		t.Errorf("Expected the fixed files to pass, got %v", err)
+	}
+}
+
+// TestBuiltinDebugStatementsOptIn tests that the auto-selected built-in
+// runner lets debug prints through unless debug-statements is enabled
+func TestBuiltinDebugStatementsOptIn(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"cli.js":  "console.log('usage: cli <file>')\n",
+		"main.go": "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	target := Target{Root: root, Files: []string{"cli.js", "main.go"}}
+
+	runner, err := detectRunner(root, Config{})
+	if err != nil {
+		t.Fatalf("detectRunner failed: %v", err)
+	}
+	if runner == nil || runner.Name() != "builtin" {
+		t.Fatalf("Expected the built-in runner to be auto-selected, got %v", runner)
+	}
+	report, err := runner.Run(target, nil)
+	if err != nil {
+		t.Errorf("Expected the auto-selected checks to pass, got %v", err)
+	}
+	for _, hook := range report.Hooks {
+		if hook.ID == "debug-statements" {
+			t.Errorf("Expected debug-statements not to run unless enabled")
+		}
+	}
+
+	runner, err = detectRunner(root, Config{Builtin: BuiltinConfig{Enable: []string{"debug-statements"}}})
+	if err != nil {
+		t.Fatalf("detectRunner failed: %v", err)
+	}
+	var failedErr *FailedError
+	if _, err := runner.Run(target, nil); !errors.As(err, &failedErr) || !reflect.DeepEqual(failedErr.Hooks, []string{"debug-statements"}) {
+		t.Errorf("Expected the enabled debug-statements check to fail, got %v", err)
+	}
+
+	runner, err = detectRunner(root, Config{})
+	if err != nil {
+		t.Fatalf("detectRunner failed: %v", err)
+	}
+	if _, err := runner.Run(target, []string{"debug-statements"}); !errors.As(err, &failedErr) {
+		t.Errorf("Expected the named debug-statements check to run, got %v", err)
+	}
+
+	if _, err := newBuiltinRunner(BuiltinConfig{Enable: []string{"debug"}}); err == nil || !strings.Contains(err.Error(), "lint.builtin.enable") {
+		t.Errorf("Expected an unknown enabled check to be rejected, got %v", err)
+	}
+}
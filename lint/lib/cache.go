@@ -0,0 +1,124 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jtamagnan/git-utils/git"
+)
+
+// cacheDir holds a file per successful lint run, relative to the git common
+// directory so that every worktree shares it
+const cacheDir = "git-lint/cache"
+
+// cacheMaxAge is how long a recorded run is kept
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// cacheVersion is part of every key, bump it when the key inputs change
+const cacheVersion = "2"
+
+// lintCache records successful lint runs so an unchanged tree is not linted twice
+type lintCache struct {
+	dir string
+}
+
+// openCache returns the lint cache of repo, whose working tree is at root
+func openCache(repo *git.Repository, root string) (*lintCache, error) {
+	gitDir, err := repo.GitExec("rev-parse", "--git-common-dir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the git directory: %v", err)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	return &lintCache{dir: filepath.Join(gitDir, cacheDir)}, nil
+}
+
+// has reports whether a run with the given key passed
+func (c *lintCache) has(key string) bool {
+	_, err := os.Stat(filepath.Join(c.dir, key))
+	return err == nil
+}
+
+// record marks the run with the given key as passed and drops expired runs
+func (c *lintCache) record(key string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create lint cache: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, key), nil, 0644); err != nil {
+		return fmt.Errorf("failed to write lint cache: %v", err)
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && time.Since(info.ModTime()) > cacheMaxAge {
+			_ = os.Remove(filepath.Join(c.dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// cacheKey identifies a lint run by what decides its result: the runner and
+// its configuration, the commit the changes start from, the tree being
+// checked, the checks, the skipped hooks and the hooks' extra environment.
+// fromCommit is empty when linting every file.
+func cacheKey(root string, runner Runner, config Config, fromCommit, tree string, checks, skip []string) string {
+	sortedChecks := append([]string(nil), checks...)
+	sort.Strings(sortedChecks)
+
+	h := sha256.New()
+	write := func(s string) {
+		_, _ = h.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+	}
+	write(cacheVersion)
+	write(runner.Name())
+	write(config.Runner)
+	write(config.Command)
+	write(config.MakeTarget)
+	write(fromCommit)
+	write(tree)
+	write(configHash(root))
+	for _, check := range sortedChecks {
+		write(check)
+	}
+	write("skip")
+	for _, hook := range skip {
+		write(hook)
+	}
+	write("env")
+	for _, entry := range config.env() {
+		write(entry)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// runnerConfigFiles are the files configuring the runners. Tracked ones are
+// already part of the tree, they are hashed too in case they are not.
+var runnerConfigFiles = append(append([]string{
+	projectConfigFile, "Makefile", "makefile", "GNUmakefile",
+}, preCommitConfigs...), lefthookConfigs...)
+
+// configHash hashes the runner config files found in root
+func configHash(root string) string {
+	h := sha256.New()
+	for _, name := range runnerConfigFiles {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		_, _ = h.Write([]byte(name + "\x00"))
+		_, _ = h.Write(content)
+		_, _ = h.Write([]byte("\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,85 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLintCache tests that a passing run is skipped when nothing that
+// decides its result changed
+func TestLintCache(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	runsFile := filepath.Join(t.TempDir(), "runs")
+	testRepo.GitExec("config", "lint.command", "echo run >> "+runsFile+"; [ ! -e fail ]")
+
+	steps := []struct {
+		name    string
+		change  func()
+		args    ParsedArgs
+		ran     bool
+		failing bool
+	}{
+		{name: "first run", ran: true},
+		{name: "unchanged tree", ran: false},
+		{name: "different checks", args: ParsedArgs{CheckNames: []string{"vet"}}, ran: true},
+		{name: "same checks again", args: ParsedArgs{CheckNames: []string{"vet"}}, ran: false},
+		{name: "all files", args: ParsedArgs{AllFiles: true}, ran: true},
+		{name: "no cache", args: ParsedArgs{NoCache: true}, ran: true},
+		{
+			name:   "staged change",
+			change: func() { testRepo.CreateFile("c.txt", "c"); testRepo.GitExec("add", "c.txt") },
+			ran:    true,
+		},
+		{
+			name:   "unstaged change",
+			change: func() { testRepo.CreateFile("c.txt", "changed") },
+			ran:    true,
+		},
+		{name: "unstaged change is never cached", ran: true},
+		{name: "staged again", change: func() { testRepo.GitExec("add", "c.txt") }, ran: true},
+		{name: "cached again", ran: false},
+		{
+			name:   "changed lint config",
+			change: func() { testRepo.GitExec("config", "lint.make-target", "lint") },
+			ran:    true,
+		},
+		{
+			name:    "failing run",
+			change:  func() { testRepo.CreateFile("fail", ""); testRepo.GitExec("add", "fail") },
+			ran:     true,
+			failing: true,
+		},
+		{name: "failing run is not cached", ran: true, failing: true},
+	}
+
+	testRepo.InDir(func() {
+		for _, step := range steps {
+			if step.change != nil {
+				step.change()
+			}
+			_ = os.Remove(runsFile)
+
+			err := Lint(step.args)
+			if step.failing && err == nil {
+				t.Errorf("%s: expected the lint run to fail", step.name)
+			}
+			if !step.failing && err != nil {
+				t.Errorf("%s: lint failed: %v", step.name, err)
+			}
+
+			_, statErr := os.Stat(runsFile)
+			if ran := statErr == nil; ran != step.ran {
+				t.Errorf("%s: expected ran=%v, got %v", step.name, step.ran, ran)
+			}
+		}
+	})
+
+	gitDir := strings.TrimSpace(testRepo.GitExec("rev-parse", "--absolute-git-dir"))
+	if entries, err := os.ReadDir(filepath.Join(gitDir, cacheDir)); err != nil || len(entries) == 0 {
+		t.Errorf("Expected passing runs to be recorded under %s, got %v, %v", cacheDir, entries, err)
+	}
+}
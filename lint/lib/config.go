@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jtamagnan/git-utils/git"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFile is the checked-in repository config shared with
+// git-review. git-lint reads its "lint" section.
+const projectConfigFile = ".git-review.yaml"
+
+// Config holds the lint settings from git config (lint.*) and the "lint"
+// section of the repository's .git-review.yaml. Git config takes precedence.
+type Config struct {
+	Runner     string `yaml:"runner"`      // runner to use, auto-detected if empty
+	Command    string `yaml:"command"`     // shell command for the "command" runner
+	MakeTarget string `yaml:"make-target"` // make target for the "make" runner
+
+	Timeout      string            `yaml:"timeout"`       // limit for a whole run, e.g. "10m"
+	CheckTimeout string            `yaml:"check-timeout"` // limit for each named check
+	Env          map[string]string `yaml:"env"`           // extra environment of the hooks, git config lint.env adds KEY=VALUE entries
+
+	Builtin BuiltinConfig `yaml:"builtin"` // settings of the "builtin" runner, only from .git-review.yaml
+
+	runTimeout   time.Duration
+	checkTimeout time.Duration
+}
+
+// gitConfigKeys maps the git config keys to the Config fields they set
+func (c *Config) gitConfigKeys() map[string]*string {
+	return map[string]*string{
+		"lint.runner":        &c.Runner,
+		"lint.command":       &c.Command,
+		"lint.make-target":   &c.MakeTarget,
+		"lint.timeout":       &c.Timeout,
+		"lint.check-timeout": &c.CheckTimeout,
+	}
+}
+
+// env returns the extra environment as KEY=VALUE entries, sorted by key
+func (c Config) env() []string {
+	var env []string
+	for key, value := range c.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// parseTimeout parses the value of a timeout setting, empty for none
+func parseTimeout(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a duration like 90s or 10m", key, value)
+	}
+	return timeout, nil
+}
+
+// LoadConfig reads the lint settings for the repository rooted at root
+func LoadConfig(repo *git.Repository, root string) (Config, error) {
+	var config Config
+
+	content, err := os.ReadFile(filepath.Join(root, projectConfigFile))
+	if err == nil {
+		var project struct {
+			Lint Config `yaml:"lint"`
+		}
+		if err := yaml.Unmarshal(content, &project); err != nil {
+			return config, fmt.Errorf("failed to read %s: %v", projectConfigFile, err)
+		}
+		config = project.Lint
+	} else if !os.IsNotExist(err) {
+		return config, fmt.Errorf("failed to read %s: %v", projectConfigFile, err)
+	}
+
+	for key, field := range config.gitConfigKeys() {
+		if value, err := repo.GetConfig(key); err == nil && value != "" {
+			*field = value
+		}
+	}
+
+	// lint.env may be given several times, one KEY=VALUE each
+	if out, err := repo.GitExec("config", "--get-all", "lint.env"); err == nil && out != "" {
+		if config.Env == nil {
+			config.Env = map[string]string{}
+		}
+		for _, entry := range strings.Split(out, "\n") {
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok || key == "" {
+				return config, fmt.Errorf("invalid lint.env %q: expected KEY=VALUE", entry)
+			}
+			config.Env[key] = value
+		}
+	}
+
+	if config.runTimeout, err = parseTimeout("lint.timeout", config.Timeout); err != nil {
+		return config, err
+	}
+	if config.checkTimeout, err = parseTimeout("lint.check-timeout", config.CheckTimeout); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
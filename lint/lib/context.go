@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// killDelay is how long a hook has to exit after SIGTERM before it is killed
+const killDelay = 10 * time.Second
+
+// interruptedError is the cause of a context cancelled by a signal
+type interruptedError struct {
+	signal os.Signal
+}
+
+func (e *interruptedError) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.signal)
+}
+
+// interruptContext returns a context that is cancelled when SIGINT or
+// SIGTERM is received, so running hooks are stopped and temporary worktrees
+// removed instead of git-lint exiting right away. stop restores the default
+// signal handling.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(&interruptedError{signal: sig})
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// withTimeout bounds ctx by timeout, if it is set. what names the bounded
+// work in the error once the timeout expires.
+func withTimeout(ctx context.Context, timeout time.Duration, what string) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%s timed out after %s", what, timeout))
+}
+
+// commandContext returns a command started in its own process group. When
+// ctx is done the group receives the signal that interrupted git-lint, or
+// SIGTERM on a timeout, and the command is killed if it has not exited
+// killDelay later.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		var sig os.Signal = syscall.SIGTERM
+		var interrupted *interruptedError
+		if errors.As(context.Cause(ctx), &interrupted) {
+			sig = interrupted.signal
+		}
+		return signalProcessGroup(cmd, sig)
+	}
+	cmd.WaitDelay = killDelay
+	return cmd
+}
@@ -2,6 +2,9 @@ module github.com/jtamagnan/git-utils/lint/lib
 
 go 1.24.1
 
-require github.com/jtamagnan/git-utils/git v0.0.0
+require (
+	github.com/jtamagnan/git-utils/git v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 replace github.com/jtamagnan/git-utils/git => ../../git
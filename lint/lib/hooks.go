@@ -0,0 +1,210 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jtamagnan/git-utils/git"
+	"gopkg.in/yaml.v3"
+)
+
+// preCommitConfigs are the config files pre-commit and prek look for
+var preCommitConfigs = []string{".pre-commit-config.yaml", ".pre-commit-config.yml"}
+
+// Hook is a hook declared in .pre-commit-config.yaml
+type Hook struct {
+	ID      string   `yaml:"id"`
+	Alias   string   `yaml:"alias"` // alternative name to run the hook by
+	Name    string   `yaml:"name"`  // only known for local hooks or when overridden
+	Repo    string   `yaml:"-"`     // repository URL, "local" or "meta"
+	Stages  []string `yaml:"stages"`
+	Files   string   `yaml:"files"`
+	Exclude string   `yaml:"exclude"`
+	Types   []string `yaml:"types"`
+	TypesOr []string `yaml:"types_or"`
+	Entry   string   `yaml:"entry"` // only set for local hooks or when overridden
+	Args    []string `yaml:"args"`
+}
+
+// fixerHooks are ids of well-known hooks that rewrite the files they check
+var fixerHooks = map[string]bool{
+	"trailing-whitespace":       true,
+	"end-of-file-fixer":         true,
+	"mixed-line-ending":         true,
+	"fix-byte-order-marker":     true,
+	"requirements-txt-fixer":    true,
+	"file-contents-sorter":      true,
+	"double-quote-string-fixer": true,
+	"black":                     true,
+	"black-jupyter":             true,
+	"isort":                     true,
+	"ruff-format":               true,
+	"autopep8":                  true,
+	"yapf":                      true,
+	"pyupgrade":                 true,
+	"prettier":                  true,
+	"gofmt":                     true,
+	"go-fmt":                    true,
+	"goimports":                 true,
+	"go-imports":                true,
+	"gofumpt":                   true,
+	"rustfmt":                   true,
+	"fmt":                       true,
+	"clang-format":              true,
+	"shfmt":                     true,
+	"terraform_fmt":             true,
+}
+
+// fixerArgs are arguments that make a linter rewrite the files it checks
+var fixerArgs = []string{"--fix", "--autofix", "--write", "-w", "--in-place"}
+
+// modifiesFiles reports whether the hook may rewrite files: a well-known
+// fixer, or a hook whose entry or args ask for fixes
+func (h Hook) modifiesFiles() bool {
+	if fixerHooks[h.ID] {
+		return true
+	}
+	for _, arg := range append(strings.Fields(h.Entry), h.Args...) {
+		if slices.Contains(fixerArgs, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// preCommitConfig is the part of .pre-commit-config.yaml git-lint reads
+type preCommitConfig struct {
+	DefaultStages []string `yaml:"default_stages"`
+	Repos         []struct {
+		Repo  string `yaml:"repo"`
+		Hooks []Hook `yaml:"hooks"`
+	} `yaml:"repos"`
+}
+
+// LoadHooks returns the hooks of the pre-commit config in root, in the order
+// they are declared. It returns nil if root has no pre-commit config.
+func LoadHooks(root string) ([]Hook, error) {
+	var content []byte
+	var name string
+	for _, candidate := range preCommitConfigs {
+		var err error
+		content, err = os.ReadFile(filepath.Join(root, candidate))
+		if err == nil {
+			name = candidate
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %v", candidate, err)
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	var config preCommitConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+
+	hooks := []Hook{}
+	for _, repo := range config.Repos {
+		for _, hook := range repo.Hooks {
+			hook.Repo = repo.Repo
+			if len(hook.Stages) == 0 {
+				hook.Stages = config.DefaultStages
+			}
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+// RepositoryHooks returns the hooks of the current repository's pre-commit
+// config
+func RepositoryHooks() ([]Hook, error) {
+	repo, err := git.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := LoadHooks(workTree.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+	if hooks == nil {
+		return nil, fmt.Errorf("no %s found", preCommitConfigs[0])
+	}
+	return hooks, nil
+}
+
+// CheckNames returns the names hooks can be run by, their ids and aliases,
+// sorted and without duplicates
+func CheckNames(hooks []Hook) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, hook := range hooks {
+		for _, name := range []string{hook.ID, hook.Alias} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateChecks returns an error naming the checks that are neither the id
+// nor the alias of one of hooks
+func validateChecks(hooks []Hook, checks []string) error {
+	known := map[string]bool{}
+	for _, name := range CheckNames(hooks) {
+		known[name] = true
+	}
+
+	var unknown []string
+	for _, check := range checks {
+		if !known[check] {
+			unknown = append(unknown, fmt.Sprintf("%q", check))
+		}
+	}
+	switch len(unknown) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("unknown check %s, run `git lint list` to see the available checks", unknown[0])
+	}
+	return fmt.Errorf("unknown checks %s, run `git lint list` to see the available checks", strings.Join(unknown, ", "))
+}
+
+// validateRunnerChecks checks the requested check names against the
+// pre-commit config in root or the built-in checks. Other runners take
+// free-form check names.
+func validateRunnerChecks(root string, runner Runner, checks []string) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	if _, ok := runner.(builtinRunner); ok {
+		var hooks []Hook
+		for _, id := range BuiltinCheckIDs() {
+			hooks = append(hooks, Hook{ID: id})
+		}
+		return validateChecks(hooks, checks)
+	}
+	if _, ok := runner.(preCommitRunner); !ok {
+		return nil
+	}
+	hooks, err := LoadHooks(root)
+	if err != nil || hooks == nil {
+		// pre-commit reports a broken or missing config itself
+		return nil
+	}
+	return validateChecks(hooks, checks)
+}
@@ -0,0 +1,123 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const hooksConfig = `default_stages: [pre-commit]
+repos:
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v5.0.0
+    hooks:
+      - id: trailing-whitespace
+      - id: check-yaml
+        stages: [pre-push]
+        exclude: ^testdata/
+  - repo: local
+    hooks:
+      - id: golangci-lint
+        name: golangci-lint
+        entry: golangci-lint run
+        language: system
+        files: \.go$
+        types: [go]
+      - id: golangci-lint
+        alias: golangci-lint-fix
+        name: golangci-lint --fix
+        entry: golangci-lint run --fix
+        language: system
+        stages: [manual]
+  - repo: meta
+    hooks:
+      - id: check-hooks-apply
+`
+
+func TestLoadHooks(t *testing.T) {
+	root := t.TempDir()
+	if hooks, err := LoadHooks(root); err != nil || hooks != nil {
+		t.Errorf("Expected no hooks without a config, got %v, %v", hooks, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".pre-commit-config.yml"), []byte(hooksConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	hooks, err := LoadHooks(root)
+	if err != nil {
+		t.Fatalf("LoadHooks failed: %v", err)
+	}
+
+	remote := "https://github.com/pre-commit/pre-commit-hooks"
+	expected := []Hook{
+		{ID: "trailing-whitespace", Repo: remote, Stages: []string{"pre-commit"}},
+		{ID: "check-yaml", Repo: remote, Stages: []string{"pre-push"}, Exclude: "^testdata/"},
+		{ID: "golangci-lint", Name: "golangci-lint", Repo: "local", Stages: []string{"pre-commit"}, Files: `\.go$`, Types: []string{"go"}, Entry: "golangci-lint run"},
+		{ID: "golangci-lint", Alias: "golangci-lint-fix", Name: "golangci-lint --fix", Repo: "local", Stages: []string{"manual"}, Entry: "golangci-lint run --fix"},
+		{ID: "check-hooks-apply", Repo: "meta", Stages: []string{"pre-commit"}},
+	}
+	if !reflect.DeepEqual(hooks, expected) {
+		t.Errorf("Expected:\n%+v\ngot:\n%+v", expected, hooks)
+	}
+
+	names := []string{"check-hooks-apply", "check-yaml", "golangci-lint", "golangci-lint-fix", "trailing-whitespace"}
+	if got := CheckNames(hooks); !reflect.DeepEqual(got, names) {
+		t.Errorf("Expected check names %v, got %v", names, got)
+	}
+}
+
+func TestValidateChecks(t *testing.T) {
+	hooks := []Hook{{ID: "gofmt"}, {ID: "golangci-lint", Alias: "lint"}}
+
+	tests := []struct {
+		checks   []string
+		expected string
+	}{
+		{checks: nil, expected: ""},
+		{checks: []string{"gofmt", "lint"}, expected: ""},
+		{checks: []string{"gofmt", "govet"}, expected: "unknown check \"govet\", run `git lint list` to see the available checks"},
+		{checks: []string{"govet", "typo"}, expected: "unknown checks \"govet\", \"typo\", run `git lint list` to see the available checks"},
+	}
+	for _, tt := range tests {
+		err := validateChecks(hooks, tt.checks)
+		if tt.expected == "" && err != nil {
+			t.Errorf("Expected %v to be valid, got %v", tt.checks, err)
+		}
+		if tt.expected != "" && (err == nil || err.Error() != tt.expected) {
+			t.Errorf("Expected %q for %v, got %v", tt.expected, tt.checks, err)
+		}
+	}
+}
+
+// TestLintUnknownCheck tests that unknown check names fail before pre-commit
+// runs
+func TestLintUnknownCheck(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.CreateFile(".pre-commit-config.yaml", hooksConfig)
+	testRepo.GitExec("config", "lint.runner", "pre-commit")
+
+	binDir := t.TempDir()
+	ranFile := filepath.Join(binDir, "ran")
+	if err := os.WriteFile(filepath.Join(binDir, "pre-commit"), []byte("#!/bin/sh\ntouch "+ranFile+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake pre-commit: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{CheckNames: []string{"golangci-lint-fix", "govet"}}); err == nil {
+			t.Errorf("Expected an error for the unknown check")
+		}
+		if _, err := os.Stat(ranFile); err == nil {
+			t.Errorf("Expected pre-commit not to run")
+		}
+
+		if err := Lint(ParsedArgs{CheckNames: []string{"golangci-lint-fix"}}); err != nil {
+			t.Errorf("Lint failed: %v", err)
+		}
+		if _, err := os.Stat(ranFile); err != nil {
+			t.Errorf("Expected pre-commit to run")
+		}
+	})
+}
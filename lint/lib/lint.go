@@ -1,11 +1,14 @@
 package lint
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/jtamagnan/git-utils/git"
 )
@@ -14,123 +17,263 @@ type ParsedArgs struct {
 	AllFiles   bool
 	Stream     bool
 	CheckNames []string
+	Jobs       int    // number of named checks run at once, 0 for one per CPU unless a check may modify files
+	NoCache    bool   // run the checks even if they passed on the same tree before
+	PerCommit  bool   // lint each commit since the upstream branch on its own
+	Autofix    bool   // fold changes made by the checks into the commits and lint again
+	Detailed   bool   // collect details of passing hooks too, for reports
+	FromRef    string // ref the changes start after, the upstream branch if empty
+	Isolated   bool   // lint HEAD in a temporary worktree, ignoring uncommitted changes
+	Fix        bool   // let the built-in checks fix what they safely can
+
+	Timeout      time.Duration // limit for the whole run, lint.timeout if 0
+	CheckTimeout time.Duration // limit for each named check, lint.check-timeout if 0
+	Skip         []string      // hooks to skip, passed as SKIP
+
+	Output io.Writer // where progress and hook output is printed, stdout if nil
 }
 
-func canLint() bool {
-	repo, err := git.GetRepository()
-	if err != nil {
-		return false
+// output returns where progress and hook output is printed
+func (args ParsedArgs) output() io.Writer {
+	if args.Output == nil {
+		return os.Stdout
 	}
+	return args.Output
+}
 
-	workTree, err := repo.Worktree()
-	if err != nil {
-		return false
+// Lint runs the repository's lint checks, against every file or against the
+// changes since the upstream branch. Repositories without a lint setup are
+// skipped with a notice. When hooks fail, the error names them and, unless
+// their output was streamed, their output is printed.
+func Lint(args ParsedArgs) error {
+	if args.PerCommit {
+		return lintPerCommit(args)
 	}
 
-	workDir := workTree.Filesystem.Root()
-	configPath := filepath.Join(workDir, ".pre-commit-config.yaml")
-
-	if _, err := os.Stat(configPath); err == nil {
-		return true
+	report, err := Run(args)
+	var failedErr *FailedError
+	if err != nil && !args.Stream && report != nil && errors.As(err, &failedErr) {
+		printFailures(args.output(), report)
 	}
+	return err
+}
 
-	configPathYml := filepath.Join(workDir, ".pre-commit-config.yml")
-	if _, err := os.Stat(configPathYml); err == nil {
-		return true
+// lintPerCommit lints each commit since the upstream branch on its own
+func lintPerCommit(args ParsedArgs) error {
+	if args.AllFiles {
+		return fmt.Errorf("--per-commit cannot be combined with --all")
+	}
+	if args.Autofix {
+		return fmt.Errorf("--per-commit cannot be combined with --autofix")
+	}
+	repo, err := git.GetRepository()
+	if err != nil {
+		return err
+	}
+	fromRef, err := args.fromRef(repo)
+	if err != nil {
+		return err
+	}
+	ranges, err := commitRanges(repo, fromRef)
+	if err != nil {
+		return err
 	}
+	return LintRanges(args, ranges)
+}
 
-	return false
+// printFailures prints the output of the hooks that failed to w
+func printFailures(w io.Writer, report *Report) {
+	for _, hook := range report.Hooks {
+		if hook.Status == StatusFailed {
+			fmt.Fprintf(w, "%s failed:\n%s\n\n", hook.Label(), hook.Output)
+		}
+	}
 }
 
-// lintCommand returns "prek" if it is installed, otherwise "pre-commit".
-func lintCommand() string {
-	if _, err := exec.LookPath("prek"); err == nil {
-		return "prek"
+// Run runs the repository's lint checks like Lint and returns the result of
+// each hook. The report is returned even when hooks fail.
+func Run(args ParsedArgs) (*Report, error) {
+	if args.PerCommit {
+		return nil, fmt.Errorf("reports are not supported with --per-commit")
 	}
-	return "pre-commit"
+	ctx, stop := interruptContext()
+	defer stop()
+	return run(ctx, args)
 }
 
-func Lint(args ParsedArgs) error {
-	if !canLint() {
-		return nil
+// runTarget returns a target at root with the settings of args and config.
+// Its context is cancelled once the run times out or cancel is called.
+func runTarget(ctx context.Context, root string, args ParsedArgs, config Config) (Target, context.CancelFunc) {
+	timeout := config.runTimeout
+	if args.Timeout > 0 {
+		timeout = args.Timeout
+	}
+	checkTimeout := config.checkTimeout
+	if args.CheckTimeout > 0 {
+		checkTimeout = args.CheckTimeout
 	}
 
-	// TODO(jat): Allow the "from-ref" to be set to a specific commit or upstream branch
+	ctx, cancel := withTimeout(ctx, timeout, "lint run")
+	return Target{
+		Root:         root,
+		AllFiles:     args.AllFiles,
+		Stream:       args.Stream,
+		Jobs:         args.Jobs,
+		Detailed:     args.Detailed,
+		CheckTimeout: checkTimeout,
+		Env:          config.env(),
+		Skip:         args.Skip,
+		Output:       args.output(),
+		ctx:          ctx,
+	}, cancel
+}
 
-	// Get the upstream branch that we're tracking. TODO(jat): Consider using a merge-base
+// run implements Run, ctx is cancelled on interrupts
+func run(ctx context.Context, args ParsedArgs) (*Report, error) {
 	repo, err := git.GetRepository()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	branch, err := repo.Head()
+	workTree, err := repo.Worktree()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	upstreamBranch, err := branch.TrackingBranch()
-	if err != nil || upstreamBranch == "" {
-		return fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+	root := workTree.Filesystem.Root()
+	if args.Isolated {
+		return runIsolated(ctx, repo, root, args)
 	}
 
-	writeTree, err := repo.WriteTree()
+	config, err := LoadConfig(repo, root)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	var baseArgs []string
-	baseArgs = append(baseArgs, "run")
-	baseArgs = append(baseArgs, "--color=always")
-
-	if args.AllFiles {
-		baseArgs = append(baseArgs, "--all-files")
-	} else {
-		baseArgs = append(baseArgs, fmt.Sprintf("--from-ref=%s", upstreamBranch))
-		baseArgs = append(baseArgs, fmt.Sprintf("--to-ref=%s", writeTree))
+	runner, err := detectRunner(root, config)
+	if err != nil {
+		return nil, err
+	}
+	if runner == nil {
+		fmt.Fprintln(args.output(), "Skipping lint checks: no pre-commit, lefthook or make configuration found, lint.command is not set and the built-in checks are disabled")
+		return &Report{}, nil
+	}
+	if err := validateRunnerChecks(root, runner, args.CheckNames); err != nil {
+		return nil, err
 	}
 
-	lintCmd := lintCommand()
-
-	// If no specific checks provided, run all checks
-	if len(args.CheckNames) == 0 {
-		return runLintCommand(lintCmd, baseArgs, args.Stream)
+	writeTree, err := repo.WriteTree()
+	if err != nil {
+		return nil, err
 	}
 
-	// Run each check separately and collect all errors
-	var errs []error
-	for _, checkName := range args.CheckNames {
-		cliArgs := make([]string, len(baseArgs))
-		copy(cliArgs, baseArgs)
-		cliArgs = append(cliArgs, checkName)
+	target, cancel := runTarget(ctx, root, args, config)
+	defer cancel()
+	fromCommit := ""
+	if !args.AllFiles {
+		fromRef, err := args.fromRef(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		target.FromRef = fromRef
+		target.ToRef = writeTree
+		target.Files, err = changedFiles(repo, fromRef, writeTree)
+		if err != nil {
+			return nil, err
+		}
 
-		err := runLintCommand(lintCmd, cliArgs, args.Stream)
+		fromCommit, err = repo.GitExec("rev-parse", fromRef)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("check %q failed: %w", checkName, err))
+			return nil, err
 		}
 	}
 
-	// Return all errors joined together, or nil if no errors
-	if len(errs) > 0 {
-		return errors.Join(errs...)
+	// Runners check the files in the working tree, so the tree hash only
+	// describes what is checked when there are no unstaged changes
+	var cache *lintCache
+	key := cacheKey(root, runner, config, fromCommit, writeTree, args.CheckNames, args.Skip)
+	if !args.NoCache && !hasUnstagedChanges(repo) {
+		cache, err = openCache(repo, root)
+		if err != nil {
+			return nil, err
+		}
+		if cache.has(key) {
+			fmt.Fprintln(args.output(), "Skipping lint checks: they already passed on this tree")
+			return &Report{Runner: runner.Name(), Cached: true}, nil
+		}
 	}
-	return nil
-}
 
-func runLintCommand(lintCmd string, cliArgs []string, stream bool) error {
-	cmd := exec.Command(lintCmd, cliArgs...)
+	// Fixes can only be told apart from the user's own changes on a clean tree
+	autofixFrom := ""
+	if args.Autofix {
+		if hasUncommittedChanges(repo) {
+			fmt.Fprintln(args.output(), "Skipping autofix: commit or stash your changes first")
+		} else if autofixFrom, err = args.fromRef(repo); err != nil {
+			return nil, err
+		}
+	}
 
-	if stream {
-		fmt.Printf("$ %s:\n", cmd.String())
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		err := cmd.Run()
-		if err != nil {
-			return fmt.Errorf("error running `%s`", cmd.String())
+	target.Fix = args.Fix || autofixFrom != ""
+	report, err := runner.Run(target, args.CheckNames)
+	if err != nil {
+		if autofixFrom == "" {
+			return report, err
 		}
-	} else {
-		out, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("error running `%s` \n%s", cmd.String(), out)
+		fixed, fixErr := autofix(repo, root, autofixFrom)
+		if fixErr != nil {
+			return report, errors.Join(err, fmt.Errorf("autofix failed: %v", fixErr))
+		}
+		if !fixed {
+			return report, err
 		}
+		fmt.Fprintln(args.output(), "Folded the lint fixes into their commits, running the lint checks again...")
+		args.Autofix = false
+		return run(ctx, args)
 	}
+	if cache != nil {
+		if err := cache.record(key); err != nil {
+			slog.Warn("failed to record lint run", "err", err)
+		}
+	}
+	return report, nil
+}
+
+// fromRef returns the ref the linted changes start after: FromRef if set,
+// otherwise the upstream branch
+func (args ParsedArgs) fromRef(repo *git.Repository) (string, error) {
+	if args.FromRef != "" {
+		return args.FromRef, nil
+	}
+	return trackingBranch(repo)
+}
 
-	return nil
+// trackingBranch returns the upstream branch of the current branch.
+// TODO(jat): Consider using a merge-base
+func trackingBranch(repo *git.Repository) (string, error) {
+	branch, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	upstreamBranch, err := branch.TrackingBranch()
+	if err != nil || upstreamBranch == "" {
+		return "", fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+	}
+	return upstreamBranch, nil
+}
+
+// hasUnstagedChanges reports whether tracked files differ from the index
+func hasUnstagedChanges(repo *git.Repository) bool {
+	_, err := repo.GitExec("diff", "--quiet")
+	return err != nil
+}
+
+// changedFiles lists the files added, copied, modified or renamed between
+// fromRef and toRef
+func changedFiles(repo *git.Repository, fromRef, toRef string) ([]string, error) {
+	out, err := repo.GitExec("diff", "--name-only", "--diff-filter=ACMR", fromRef, toRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %v", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
 }
@@ -33,6 +33,7 @@ func TestParsedArgsStructure(t *testing.T) {
 func TestLintWithEmptyRepo(t *testing.T) {
 	testRepo := git.NewTestRepo(t)
 	defer testRepo.Cleanup()
+	testRepo.CreateFile(".git-review.yaml", "lint:\n  builtin:\n    enabled: false\n")
 
 	testRepo.InDir(func() {
 		args := ParsedArgs{
@@ -42,7 +43,7 @@ func TestLintWithEmptyRepo(t *testing.T) {
 		}
 
 		err := Lint(args)
-		// Should pass because canLint() returns false for repos without pre-commit config
+		// Should pass because there is no lint setup and the built-in checks are disabled
 		if err != nil {
 			t.Errorf("Expected no error for empty repository without pre-commit config, but got: %v", err)
 		}
@@ -300,31 +301,96 @@ func TestLintCommand(t *testing.T) {
 	}
 }
 
-// TestCanLint tests the canLint function with and without pre-commit config
-func TestCanLint(t *testing.T) {
+// TestDetectRunner tests which runner is picked for each lint setup
+func TestDetectRunner(t *testing.T) {
 	tests := []struct {
-		name           string
-		configFile     string
-		configContent  string
-		expectedResult bool
+		name         string
+		files        map[string]string
+		gitConfig    map[string]string
+		expectedName string // empty if no runner should be found
+		expectError  bool
 	}{
 		{
-			name:           "WithYamlConfig",
-			configFile:     ".pre-commit-config.yaml",
-			configContent:  "repos: []",
-			expectedResult: true,
+			name:         "WithYamlConfig",
+			files:        map[string]string{".pre-commit-config.yaml": "repos: []"},
+			expectedName: lintCommand(),
 		},
 		{
-			name:           "WithYmlConfig",
-			configFile:     ".pre-commit-config.yml",
-			configContent:  "repos: []",
-			expectedResult: true,
+			name:         "WithYmlConfig",
+			files:        map[string]string{".pre-commit-config.yml": "repos: []"},
+			expectedName: lintCommand(),
 		},
 		{
-			name:           "NoConfig",
-			configFile:     "",
-			configContent:  "",
-			expectedResult: false,
+			name:         "NoConfig",
+			expectedName: "builtin",
+		},
+		{
+			name:  "BuiltinDisabled",
+			files: map[string]string{".git-review.yaml": "lint:\n  builtin:\n    enabled: false\n"},
+		},
+		{
+			name:        "BuiltinUnknownSkip",
+			files:       map[string]string{".git-review.yaml": "lint:\n  builtin:\n    skip: [typo]\n"},
+			expectError: true,
+		},
+		{
+			name:         "Lefthook",
+			files:        map[string]string{"lefthook.yml": "pre-commit:\n  commands: {}\n"},
+			expectedName: "lefthook",
+		},
+		{
+			name:         "PreCommitBeforeLefthook",
+			files:        map[string]string{".pre-commit-config.yaml": "repos: []", ".lefthook.yml": ""},
+			expectedName: lintCommand(),
+		},
+		{
+			name:         "MakeCheckTarget",
+			files:        map[string]string{"Makefile": "check:\n\ttrue\n"},
+			expectedName: "make check",
+		},
+		{
+			name:         "MakefileWithoutCheckTarget",
+			files:        map[string]string{"Makefile": "build:\n\ttrue\n"},
+			expectedName: "builtin",
+		},
+		{
+			name:         "ConfiguredMakeTarget",
+			files:        map[string]string{"Makefile": "lint:\n\ttrue\n"},
+			gitConfig:    map[string]string{"lint.make-target": "lint"},
+			expectedName: "make lint",
+		},
+		{
+			name:         "CommandFromGitConfig",
+			files:        map[string]string{".pre-commit-config.yaml": "repos: []"},
+			gitConfig:    map[string]string{"lint.command": "./lint.sh"},
+			expectedName: "./lint.sh",
+		},
+		{
+			name:         "CommandFromProjectConfig",
+			files:        map[string]string{".git-review.yaml": "lint:\n  command: ./lint.sh\n"},
+			expectedName: "./lint.sh",
+		},
+		{
+			name:         "GitConfigOverridesProjectConfig",
+			files:        map[string]string{".git-review.yaml": "lint:\n  command: ./lint.sh\n"},
+			gitConfig:    map[string]string{"lint.command": "./other.sh"},
+			expectedName: "./other.sh",
+		},
+		{
+			name:         "ExplicitRunner",
+			files:        map[string]string{".pre-commit-config.yaml": "repos: []"},
+			gitConfig:    map[string]string{"lint.runner": "lefthook"},
+			expectedName: "lefthook",
+		},
+		{
+			name:        "CommandRunnerWithoutCommand",
+			gitConfig:   map[string]string{"lint.runner": "command"},
+			expectError: true,
+		},
+		{
+			name:        "UnknownRunner",
+			gitConfig:   map[string]string{"lint.runner": "tox"},
+			expectError: true,
 		},
 	}
 
@@ -334,17 +400,35 @@ func TestCanLint(t *testing.T) {
 			defer testRepo.Cleanup()
 
 			testRepo.AddCommit("test.txt", "test content", "Initial commit")
-
-			if tt.configFile != "" {
-				testRepo.CreateFile(tt.configFile, tt.configContent)
+			for file, content := range tt.files {
+				testRepo.CreateFile(file, content)
+			}
+			for key, value := range tt.gitConfig {
+				testRepo.GitExec("config", key, value)
 			}
 
-			testRepo.InDir(func() {
-				result := canLint()
-				if result != tt.expectedResult {
-					t.Errorf("Expected canLint() to return %v, got %v", tt.expectedResult, result)
+			config, err := LoadConfig(testRepo.Repo, testRepo.Dir)
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+			runner, err := detectRunner(testRepo.Dir, config)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got runner %v", runner)
 				}
-			})
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectRunner failed: %v", err)
+			}
+
+			name := ""
+			if runner != nil {
+				name = runner.Name()
+			}
+			if name != tt.expectedName {
+				t.Errorf("Expected runner %q, got %q", tt.expectedName, name)
+			}
 		})
 	}
 }
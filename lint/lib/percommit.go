@@ -0,0 +1,217 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jtamagnan/git-utils/git"
+)
+
+// Range is a commit linted on its own by LintRanges, against the changes
+// since FromRef
+type Range struct {
+	Name    string // how failures refer to the range, e.g. "PR #12"
+	FromRef string // commit the changes start after, empty to lint every file
+	ToRef   string // commit that is checked out and linted
+}
+
+// commitRanges returns a range for each commit between fromRef and HEAD,
+// oldest first, each checking the changes of that commit only
+func commitRanges(repo *git.Repository, fromRef string) ([]Range, error) {
+	out, err := repo.GitExec("log", "--reverse", "--format=%H %h %s", fromRef+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %v", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+
+	var ranges []Range
+	previous := fromRef
+	for _, line := range strings.Split(out, "\n") {
+		hash, rest, _ := strings.Cut(line, " ")
+		short, summary, _ := strings.Cut(rest, " ")
+		ranges = append(ranges, Range{
+			Name:    fmt.Sprintf("commit %s (%s)", short, summary),
+			FromRef: previous,
+			ToRef:   hash,
+		})
+		previous = hash
+	}
+	return ranges, nil
+}
+
+// LintRanges lints each range in a temporary worktree checked out at its
+// ToRef, so every commit has to pass on its own rather than only the tip of
+// the branch. All ranges are linted and every failure names its range.
+func LintRanges(args ParsedArgs, ranges []Range) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+
+	repo, err := git.GetRepository()
+	if err != nil {
+		return err
+	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	root := workTree.Filesystem.Root()
+
+	var cache *lintCache
+	if !args.NoCache {
+		cache, err = openCache(repo, root)
+		if err != nil {
+			return err
+		}
+	}
+
+	dir, cleanup, err := addWorktree(repo, ranges[0].ToRef)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var errs []error
+	for _, r := range ranges {
+		report, err := lintRange(ctx, repo, dir, cache, args, r)
+		if err != nil {
+			var failedErr *FailedError
+			if !args.Stream && report != nil && errors.As(err, &failedErr) {
+				printFailures(args.output(), report)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", r.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// addWorktree creates a temporary worktree detached at ref. cleanup removes
+// it again.
+func addWorktree(repo *git.Repository, ref string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "git-lint-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary worktree: %v", err)
+	}
+	if _, err := repo.GitExec("worktree", "add", "--detach", dir, ref); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to create temporary worktree: %v", err)
+	}
+	cleanup := func() {
+		if _, err := repo.GitExec("worktree", "remove", "--force", dir); err != nil {
+			slog.Warn("failed to remove temporary worktree", "dir", dir, "err", err)
+		}
+		_ = os.RemoveAll(dir)
+	}
+	return dir, cleanup, nil
+}
+
+// lintRange checks out r.ToRef in the worktree at dir and lints the changes
+// since r.FromRef, or every file if r.FromRef is empty
+func lintRange(ctx context.Context, repo *git.Repository, dir string, cache *lintCache, args ParsedArgs, r Range) (*Report, error) {
+	fromCommit := ""
+	if r.FromRef != "" {
+		var err error
+		fromCommit, err = repo.GitExec("rev-parse", "--verify", r.FromRef+"^{commit}")
+		if err != nil {
+			return nil, err
+		}
+	}
+	toCommit, err := repo.GitExec("rev-parse", "--verify", r.ToRef+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	tree, err := repo.GitExec("rev-parse", toCommit+"^{tree}")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GitExec("-C", dir, "checkout", "--quiet", "--force", "--detach", toCommit); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %v", toCommit, err)
+	}
+
+	// The commit's own lint setup applies, it may differ along the stack
+	config, err := LoadConfig(repo, dir)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := detectRunner(dir, config)
+	if err != nil {
+		return nil, err
+	}
+	if runner == nil {
+		fmt.Fprintf(args.output(), "Skipping lint checks for %s: no lint configuration found\n", r.Name)
+		return &Report{}, nil
+	}
+	if err := validateRunnerChecks(dir, runner, args.CheckNames); err != nil {
+		return nil, err
+	}
+
+	key := cacheKey(dir, runner, config, fromCommit, tree, args.CheckNames, args.Skip)
+	if cache != nil && cache.has(key) {
+		fmt.Fprintf(args.output(), "Skipping lint checks for %s: they already passed on this tree\n", r.Name)
+		return &Report{Runner: runner.Name(), Cached: true}, nil
+	}
+
+	target, cancel := runTarget(ctx, dir, args, config)
+	defer cancel()
+	target.AllFiles = fromCommit == ""
+	if fromCommit != "" {
+		target.FromRef = fromCommit
+		target.ToRef = toCommit
+		target.Files, err = changedFiles(repo, fromCommit, toCommit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(args.output(), "Linting %s...\n", r.Name)
+	report, err := runner.Run(target, args.CheckNames)
+	if err != nil {
+		return report, err
+	}
+
+	if cache != nil {
+		if err := cache.record(key); err != nil {
+			slog.Warn("failed to record lint run", "err", err)
+		}
+	}
+	return report, nil
+}
+
+// runIsolated lints HEAD in a temporary worktree, so uncommitted changes do
+// not affect the result
+func runIsolated(ctx context.Context, repo *git.Repository, root string, args ParsedArgs) (*Report, error) {
+	if args.Autofix {
+		fmt.Fprintln(args.output(), "Skipping autofix: it is not supported with an isolated lint")
+	}
+	r := Range{Name: "HEAD", ToRef: "HEAD"}
+	if !args.AllFiles {
+		fromRef, err := args.fromRef(repo)
+		if err != nil {
+			return nil, err
+		}
+		r.FromRef = fromRef
+	}
+
+	var cache *lintCache
+	if !args.NoCache {
+		var err error
+		cache, err = openCache(repo, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dir, cleanup, err := addWorktree(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return lintRange(ctx, repo, dir, cache, args, r)
+}
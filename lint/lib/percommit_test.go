@@ -0,0 +1,122 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLintPerCommit tests that a commit broken in the middle of a branch
+// fails per-commit lint even though the tip passes
+func TestLintPerCommit(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.GitExec("commit", "-q", "-m", "Add b")
+	testRepo.AddCommit("bad", "", "Break the build")
+	testRepo.GitExec("rm", "-q", "bad")
+	testRepo.GitExec("commit", "-q", "-m", "Fix the build")
+
+	filesLog := filepath.Join(t.TempDir(), "files")
+	testRepo.GitExec("config", "lint.command",
+		`echo "$LINT_FILES" | tr '\n' ' ' >> `+filesLog+`; echo >> `+filesLog+`; [ ! -e bad ]`)
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{}); err != nil {
+			t.Fatalf("Expected the tip of the branch to pass, got: %v", err)
+		}
+
+		err := Lint(ParsedArgs{PerCommit: true})
+		if err == nil {
+			t.Fatal("Expected per-commit lint to fail on the broken commit")
+		}
+		if !strings.Contains(err.Error(), "(Break the build)") {
+			t.Errorf("Expected the error to name the broken commit, got: %v", err)
+		}
+		if strings.Contains(err.Error(), "(Add a)") || strings.Contains(err.Error(), "(Fix the build)") {
+			t.Errorf("Expected only the broken commit to fail, got: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(filesLog)
+	if err != nil {
+		t.Fatalf("Failed to read the lint log: %v", err)
+	}
+	runs := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	expected := []string{"a.txt b.txt", "a.txt", "b.txt", "bad", ""}
+	if len(runs) != len(expected) {
+		t.Fatalf("Expected %d runs, got %q", len(expected), runs)
+	}
+	for i := range expected {
+		if strings.TrimSpace(runs[i]) != expected[i] {
+			t.Errorf("Run %d: expected files %q, got %q", i, expected[i], strings.TrimSpace(runs[i]))
+		}
+	}
+
+	if worktrees := testRepo.GitExec("worktree", "list"); strings.Count(worktrees, "\n") > 1 {
+		t.Errorf("Expected the temporary worktree to be removed, got:\n%s", worktrees)
+	}
+}
+
+// TestLintRangesCache tests that ranges that passed before are skipped
+func TestLintRangesCache(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.GitExec("commit", "-q", "-m", "Add b")
+
+	runsFile := filepath.Join(t.TempDir(), "runs")
+	testRepo.GitExec("config", "lint.command", "echo run >> "+runsFile)
+
+	ranges := []Range{
+		{Name: "PR #1", FromRef: "origin/main", ToRef: "HEAD~1"},
+		{Name: "PR #2", FromRef: "HEAD~1", ToRef: "HEAD"},
+	}
+	testRepo.InDir(func() {
+		for i := 0; i < 2; i++ {
+			if err := LintRanges(ParsedArgs{}, ranges); err != nil {
+				t.Fatalf("LintRanges failed: %v", err)
+			}
+		}
+	})
+
+	content, err := os.ReadFile(runsFile)
+	if err != nil {
+		t.Fatalf("Failed to read the lint log: %v", err)
+	}
+	if runs := strings.Count(string(content), "run"); runs != 2 {
+		t.Errorf("Expected each range to be linted once, got %d runs", runs)
+	}
+}
+
+// TestLintIsolated tests that an isolated lint checks HEAD and ignores
+// staged and unstaged changes
+func TestLintIsolated(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.CreateFile("a.txt", "broken")
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	testRepo.GitExec("config", "lint.command",
+		`printf '%s|%s' "$LINT_FILES" "$(cat a.txt)" > `+envFile+`; test ! -e b.txt`)
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{Isolated: true}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+		content, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatalf("Command did not run: %v", err)
+		}
+		if expected := "a.txt|a"; string(content) != expected {
+			t.Errorf("Expected HEAD to be linted with %q, got %q", expected, string(content))
+		}
+
+		if err := Lint(ParsedArgs{}); err == nil {
+			t.Errorf("Expected the lint of the working tree to see the staged b.txt and fail")
+		}
+	})
+
+	if worktrees := testRepo.GitExec("worktree", "list"); strings.Contains(worktrees, "\n") {
+		t.Errorf("Expected the temporary worktree to be removed, got:\n%s", worktrees)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package lint
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing, process groups are unix only
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup stops cmd, signals other than kill are unix only
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package lint
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in its own process group, so that signals
+// reach every process a hook starts
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup sends sig to the process group of cmd
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return cmd.Process.Signal(sig)
+	}
+	return syscall.Kill(-cmd.Process.Pid, unixSig)
+}
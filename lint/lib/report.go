@@ -0,0 +1,384 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of one hook
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// HookResult is the outcome of one lint hook
+type HookResult struct {
+	ID       string // hook id, e.g. "golangci-lint"
+	Name     string // name shown by the runner, often more descriptive than the id
+	Status   Status
+	Duration time.Duration // zero if the runner did not report it
+	Files    []string      // checked files mentioned in the hook's output
+	Output   string        // output of the hook, without colors
+	Modified bool          // the hook changed files, e.g. a formatter
+}
+
+// Label returns the id of the hook, or its name if the runner did not
+// print the id
+func (h HookResult) Label() string {
+	if h.ID != "" {
+		return h.ID
+	}
+	return h.Name
+}
+
+// Report holds the result of every hook of a lint run
+type Report struct {
+	Runner string
+	Hooks  []HookResult
+	Cached bool // the checks passed on the same tree before and were not run
+}
+
+// Failed returns the labels of the hooks that failed
+func (r *Report) Failed() []string {
+	var failed []string
+	for _, hook := range r.Hooks {
+		if hook.Status == StatusFailed {
+			failed = append(failed, hook.Label())
+		}
+	}
+	return failed
+}
+
+// FailedError is returned when hooks fail, it names them instead of
+// repeating their output
+type FailedError struct {
+	Hooks []string
+}
+
+func (e *FailedError) Error() string {
+	quoted := make([]string, len(e.Hooks))
+	for i, hook := range e.Hooks {
+		quoted[i] = "`" + hook + "`"
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("hook %s failed", quoted[0])
+	}
+	return fmt.Sprintf("hooks %s and %s failed", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// hasFailedHook reports whether any of hooks failed
+func hasFailedHook(hooks []HookResult) bool {
+	for _, hook := range hooks {
+		if hook.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// "trim trailing whitespace.....(no files to check)Skipped"
+	hookLinePattern = regexp.MustCompile(`^(.*?)\.+(?:\(([^)]*)\))?(Passed|Failed|Skipped)$`)
+)
+
+// stripANSI removes terminal colors from output
+func stripANSI(output string) string {
+	return ansiPattern.ReplaceAllString(output, "")
+}
+
+// parseHookResults parses the output of `pre-commit run` or `prek run` into a
+// result per hook. files are the checked files, those mentioned in a hook's
+// output are listed in its result.
+func parseHookResults(output string, files []string) []HookResult {
+	var hooks []HookResult
+	var body []string
+	inDetails := false
+
+	finish := func() {
+		if len(hooks) == 0 {
+			return
+		}
+		hook := &hooks[len(hooks)-1]
+		hook.Output = strings.TrimSpace(strings.Join(body, "\n"))
+		hook.Files = mentionedFiles(hook.Output, files)
+		body = nil
+	}
+
+	for _, line := range strings.Split(stripANSI(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if match := hookLinePattern.FindStringSubmatch(line); match != nil {
+			finish()
+			hooks = append(hooks, HookResult{
+				Name:   strings.TrimSpace(match[1]),
+				Status: Status(strings.ToLower(match[3])),
+			})
+			inDetails = true
+			continue
+		}
+		if len(hooks) == 0 {
+			continue
+		}
+
+		hook := &hooks[len(hooks)-1]
+		if inDetails && strings.HasPrefix(line, "- ") {
+			key, value, _ := strings.Cut(strings.TrimPrefix(line, "- "), ":")
+			value = strings.TrimSpace(value)
+			switch key {
+			case "hook id":
+				hook.ID = value
+			case "duration":
+				if seconds, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64); err == nil {
+					hook.Duration = time.Duration(seconds * float64(time.Second))
+				}
+			case "files were modified by this hook":
+				hook.Modified = true
+			}
+			continue
+		}
+		inDetails = false
+		body = append(body, line)
+	}
+	finish()
+	return hooks
+}
+
+// mentionedFiles returns the files that appear in output
+func mentionedFiles(output string, files []string) []string {
+	var mentioned []string
+	for _, file := range files {
+		if strings.Contains(output, file) {
+			mentioned = append(mentioned, file)
+		}
+	}
+	return mentioned
+}
+
+// Formats are the accepted values of `git lint --format`
+var Formats = []string{"text", "json", "junit", "sarif"}
+
+// Write writes the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return r.WriteJSON(w)
+	case "junit":
+		return r.WriteJUnit(w)
+	case "sarif":
+		return r.WriteSARIF(w)
+	case "text", "":
+		return r.WriteText(w)
+	}
+	return fmt.Errorf("invalid format %q: expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// WriteText writes a line per hook
+func (r *Report) WriteText(w io.Writer) error {
+	for _, hook := range r.Hooks {
+		if _, err := fmt.Fprintf(w, "%-8s %s\n", hook.Status, hook.Label()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonHook struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Status   Status   `json:"status"`
+	Duration float64  `json:"duration"` // seconds
+	Files    []string `json:"files"`
+	Output   string   `json:"output"`
+	Modified bool     `json:"modified"`
+}
+
+// WriteJSON writes the report as {"runner": ..., "hooks": [...]}
+func (r *Report) WriteJSON(w io.Writer) error {
+	hooks := make([]jsonHook, len(r.Hooks))
+	for i, hook := range r.Hooks {
+		files := hook.Files
+		if files == nil {
+			files = []string{}
+		}
+		hooks[i] = jsonHook{
+			ID:       hook.ID,
+			Name:     hook.Name,
+			Status:   hook.Status,
+			Duration: hook.Duration.Seconds(),
+			Files:    files,
+			Output:   hook.Output,
+			Modified: hook.Modified,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Runner string     `json:"runner"`
+		Cached bool       `json:"cached"`
+		Hooks  []jsonHook `json:"hooks"`
+	}{r.Runner, r.Cached, hooks})
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML test suite with a test case
+// per hook
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{Name: "git-lint", Tests: len(r.Hooks)}
+	var total time.Duration
+	for _, hook := range r.Hooks {
+		total += hook.Duration
+		testCase := junitTestCase{
+			Name:      hook.Label(),
+			ClassName: r.Runner,
+			Time:      seconds(hook.Duration),
+		}
+		switch hook.Status {
+		case StatusFailed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s failed", hook.Label()), Text: hook.Output}
+		case StatusSkipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: hook.Output}
+		default:
+			testCase.SystemOut = hook.Output
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds formats a duration for JUnit
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes the report as SARIF 2.1.0, with a rule per hook and a
+// result per failed hook located at the files its output mentions
+func (r *Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "git-lint", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	for _, hook := range r.Hooks {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: hook.Label(), Name: hook.Name})
+		if hook.Status != StatusFailed {
+			continue
+		}
+
+		message := hook.Output
+		if message == "" {
+			message = fmt.Sprintf("%s failed", hook.Label())
+		}
+		result := sarifResult{RuleID: hook.Label(), Level: "error", Message: sarifMessage{Text: message}}
+		for _, file := range hook.Files {
+			result.Locations = append(result.Locations, sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}},
+			})
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,174 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// preCommitOutput is `pre-commit run --verbose --color=always` output
+const preCommitOutput = "[INFO] Initializing environment for https://github.com/pre-commit/pre-commit-hooks.\n" +
+	"trim trailing whitespace.................................................\x1b[42mPassed\x1b[m\n" +
+	"- hook id: trailing-whitespace\n" +
+	"- duration: 0.05s\n" +
+	"check yaml...........................................\x1b[46;30m(no files to check)\x1b[m\x1b[46;30mSkipped\x1b[m\n" +
+	"- hook id: check-yaml\n" +
+	"gofmt....................................................................\x1b[41mFailed\x1b[m\n" +
+	"- hook id: gofmt\n" +
+	"- duration: 0.12s\n" +
+	"- exit code: 1\n" +
+	"- files were modified by this hook\n" +
+	"\n" +
+	"main.go\n" +
+	"\n" +
+	"golangci-lint............................................................\x1b[41mFailed\x1b[m\n" +
+	"- hook id: golangci-lint\n" +
+	"- exit code: 1\n" +
+	"\n" +
+	"lib/x.go:3:1: unused variable\n"
+
+func TestParseHookResults(t *testing.T) {
+	hooks := parseHookResults(preCommitOutput, []string{"main.go", "lib/x.go", "README.md"})
+
+	expected := []HookResult{
+		{ID: "trailing-whitespace", Name: "trim trailing whitespace", Status: StatusPassed, Duration: 50 * time.Millisecond},
+		{ID: "check-yaml", Name: "check yaml", Status: StatusSkipped},
+		{ID: "gofmt", Name: "gofmt", Status: StatusFailed, Duration: 120 * time.Millisecond, Files: []string{"main.go"}, Output: "main.go", Modified: true},
+		{ID: "golangci-lint", Name: "golangci-lint", Status: StatusFailed, Files: []string{"lib/x.go"}, Output: "lib/x.go:3:1: unused variable"},
+	}
+	if !reflect.DeepEqual(hooks, expected) {
+		t.Errorf("Expected:\n%+v\ngot:\n%+v", expected, hooks)
+	}
+}
+
+func TestFailedErrorMessage(t *testing.T) {
+	tests := []struct {
+		hooks    []string
+		expected string
+	}{
+		{hooks: []string{"gofmt"}, expected: "hook `gofmt` failed"},
+		{hooks: []string{"gofmt", "golangci-lint"}, expected: "hooks `gofmt` and `golangci-lint` failed"},
+		{hooks: []string{"a", "b", "c"}, expected: "hooks `a`, `b` and `c` failed"},
+	}
+	for _, tt := range tests {
+		if message := (&FailedError{Hooks: tt.hooks}).Error(); message != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, message)
+		}
+	}
+}
+
+// TestPreCommitRunnerReport tests that failed hooks are named in the error
+// with a fake pre-commit on PATH
+func TestPreCommitRunnerReport(t *testing.T) {
+	binDir := t.TempDir()
+	outputFile := filepath.Join(binDir, "output")
+	if err := os.WriteFile(outputFile, []byte(preCommitOutput), 0644); err != nil {
+		t.Fatalf("Failed to write output: %v", err)
+	}
+	script := "#!/bin/sh\ncat " + outputFile + "\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "pre-commit"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake pre-commit: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	report, err := preCommitRunner{command: "pre-commit"}.Run(Target{Root: binDir, AllFiles: true}, nil)
+	if err == nil || err.Error() != "hooks `gofmt` and `golangci-lint` failed" {
+		t.Errorf("Expected the failed hooks to be named, got: %v", err)
+	}
+	if report == nil || len(report.Hooks) != 4 || report.Runner != "pre-commit" {
+		t.Fatalf("Expected a report with 4 hooks, got %+v", report)
+	}
+}
+
+func testReport() *Report {
+	return &Report{Runner: "pre-commit", Hooks: []HookResult{
+		{ID: "trailing-whitespace", Name: "trim trailing whitespace", Status: StatusPassed, Duration: 50 * time.Millisecond},
+		{ID: "check-yaml", Name: "check yaml", Status: StatusSkipped},
+		{ID: "gofmt", Name: "gofmt", Status: StatusFailed, Files: []string{"main.go"}, Output: "main.go"},
+	}}
+}
+
+func TestReportJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := testReport().Write(&out, "json"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var decoded struct {
+		Runner string `json:"runner"`
+		Hooks  []struct {
+			ID       string   `json:"id"`
+			Status   string   `json:"status"`
+			Duration float64  `json:"duration"`
+			Files    []string `json:"files"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if decoded.Runner != "pre-commit" || len(decoded.Hooks) != 3 {
+		t.Fatalf("Unexpected report: %s", out.String())
+	}
+	if hook := decoded.Hooks[0]; hook.ID != "trailing-whitespace" || hook.Status != "passed" || hook.Duration != 0.05 || hook.Files == nil {
+		t.Errorf("Unexpected first hook: %+v", hook)
+	}
+	if hook := decoded.Hooks[2]; hook.Status != "failed" || !reflect.DeepEqual(hook.Files, []string{"main.go"}) {
+		t.Errorf("Unexpected failed hook: %+v", hook)
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	var out bytes.Buffer
+	if err := testReport().Write(&out, "junit"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var decoded junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, out.String())
+	}
+	suite := decoded.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("Expected 3 tests, 1 failure and 1 skipped, got %+v", suite)
+	}
+	if suite.Cases[2].Name != "gofmt" || suite.Cases[2].Failure == nil || suite.Cases[2].Failure.Text != "main.go" {
+		t.Errorf("Expected gofmt to fail with its output, got %+v", suite.Cases[2])
+	}
+}
+
+func TestReportSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := testReport().Write(&out, "sarif"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var decoded sarifLog
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if decoded.Version != "2.1.0" || len(decoded.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", out.String())
+	}
+	run := decoded.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 || len(run.Results) != 1 {
+		t.Fatalf("Expected 3 rules and 1 result, got %s", out.String())
+	}
+	result := run.Results[0]
+	if result.RuleID != "gofmt" || result.Level != "error" || len(result.Locations) != 1 ||
+		result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "main.go" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestReportInvalidFormat(t *testing.T) {
+	var out bytes.Buffer
+	if err := testReport().Write(&out, "xml"); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected an invalid format error, got %v", err)
+	}
+}
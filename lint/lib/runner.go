@@ -0,0 +1,475 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target describes what a runner should check: either every file, or the
+// changes between FromRef and ToRef
+type Target struct {
+	Root     string   // root of the working tree, where runners are started
+	AllFiles bool     // check every file instead of the changes
+	FromRef  string   // ref the changes are relative to, empty with AllFiles
+	ToRef    string   // commit or tree holding the changes, empty with AllFiles
+	Files    []string // files changed between FromRef and ToRef
+	Stream   bool     // stream the runner's output instead of only reporting it on failure
+	Jobs     int      // number of named checks run at once, 0 for defaultJobs
+	Detailed bool     // ask the runner for details of passing hooks too, for reports
+	Fix      bool     // let the built-in checks fix what they safely can
+
+	CheckTimeout time.Duration // limit for each named check, 0 for none
+	Env          []string      // extra environment of the hooks, as KEY=VALUE
+	Skip         []string      // hooks to skip, passed to pre-commit and prek as SKIP
+	Output       io.Writer     // where progress and streamed output is printed, stdout if nil
+
+	ctx context.Context // cancelled on interrupts and when the run times out
+}
+
+// output returns where progress and streamed output is printed
+func (t Target) output() io.Writer {
+	if t.Output == nil {
+		return os.Stdout
+	}
+	return t.Output
+}
+
+// context returns the context the target's commands run in
+func (t Target) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// Runner runs the checks of one lint tool
+type Runner interface {
+	// Name identifies the runner in messages, e.g. "pre-commit"
+	Name() string
+	// Run runs the named checks, or every check if checks is empty, and
+	// reports the result of each hook
+	Run(target Target, checks []string) (*Report, error)
+}
+
+// Runners are the names accepted by lint.runner
+var Runners = []string{"pre-commit", "prek", "lefthook", "make", "command", "builtin"}
+
+// lefthookConfigs are the config files lefthook looks for
+var lefthookConfigs = []string{
+	"lefthook.yml", "lefthook.yaml", ".lefthook.yml", ".lefthook.yaml",
+	"lefthook.toml", ".lefthook.toml", "lefthook.json", ".lefthook.json",
+}
+
+// defaultMakeTarget is the make target run when lint.make-target is not set
+const defaultMakeTarget = "check"
+
+// makeTarget returns the configured make target or the default one
+func (c Config) makeTarget() string {
+	if c.MakeTarget == "" {
+		return defaultMakeTarget
+	}
+	return c.MakeTarget
+}
+
+// fileExists reports whether one of names exists in dir
+func fileExists(dir string, names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMakeTarget reports whether the Makefile in dir defines target.
+// `make -q` exits with 2 when there is no rule for the target.
+func hasMakeTarget(dir, target string) bool {
+	if !fileExists(dir, "Makefile", "makefile", "GNUmakefile") {
+		return false
+	}
+	cmd := exec.Command("make", "-q", target)
+	cmd.Dir = dir
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	return err == nil || (errors.As(err, &exitErr) && exitErr.ExitCode() == 1)
+}
+
+// newRunner returns the runner with the given name
+func newRunner(name string, config Config) (Runner, error) {
+	switch name {
+	case "pre-commit", "prek":
+		return preCommitRunner{command: name}, nil
+	case "lefthook":
+		return lefthookRunner{}, nil
+	case "make":
+		return makeRunner{target: config.makeTarget()}, nil
+	case "command":
+		if config.Command == "" {
+			return nil, fmt.Errorf("lint.runner is \"command\" but lint.command is not set")
+		}
+		return commandRunner{command: config.Command}, nil
+	case "builtin":
+		return newBuiltinRunner(config.Builtin)
+	}
+	return nil, fmt.Errorf("unknown lint.runner %q: expected one of %s", name, strings.Join(Runners, ", "))
+}
+
+// detectRunner picks the runner for the repository rooted at root: the
+// configured one, otherwise lint.command, pre-commit/prek, lefthook, make or
+// the built-in checks, in that order. It returns nil if the repository has no
+// lint setup and the built-in checks are disabled.
+func detectRunner(root string, config Config) (Runner, error) {
+	switch {
+	case config.Runner != "":
+		return newRunner(config.Runner, config)
+	case config.Command != "":
+		return newRunner("command", config)
+	case fileExists(root, preCommitConfigs...):
+		return newRunner(lintCommand(), config)
+	case fileExists(root, lefthookConfigs...):
+		return newRunner("lefthook", config)
+	case hasMakeTarget(root, config.makeTarget()):
+		return newRunner("make", config)
+	case config.Builtin.enabled():
+		return newRunner("builtin", config)
+	}
+	return nil, nil
+}
+
+// lintCommand returns "prek" if it is installed, otherwise "pre-commit".
+func lintCommand() string {
+	if _, err := exec.LookPath("prek"); err == nil {
+		return "prek"
+	}
+	return "pre-commit"
+}
+
+// preCommitRunner runs pre-commit or prek hooks
+type preCommitRunner struct {
+	command string
+}
+
+func (r preCommitRunner) Name() string {
+	return r.command
+}
+
+func (r preCommitRunner) Run(target Target, checks []string) (*Report, error) {
+	var baseArgs []string
+	baseArgs = append(baseArgs, "run")
+	baseArgs = append(baseArgs, "--color=always")
+	if target.Detailed {
+		// Hook ids and durations are only printed for passing hooks with --verbose
+		baseArgs = append(baseArgs, "--verbose")
+	}
+
+	if target.AllFiles {
+		baseArgs = append(baseArgs, "--all-files")
+	} else {
+		baseArgs = append(baseArgs, fmt.Sprintf("--from-ref=%s", target.FromRef))
+		baseArgs = append(baseArgs, fmt.Sprintf("--to-ref=%s", target.ToRef))
+	}
+
+	var results []commandResult
+	if len(checks) == 0 {
+		// If no specific checks provided, run all checks
+		results = []commandResult{runLintCommand(target.context(), lintExec(target.context(), target, r.command, baseArgs...), target)}
+	} else {
+		// Run each check separately and collect all errors
+		if target.Jobs == 0 {
+			target.Jobs = defaultJobs(target, checks)
+		}
+		results = runChecks(target, checks, func(ctx context.Context, checkName string) *exec.Cmd {
+			cliArgs := make([]string, len(baseArgs))
+			copy(cliArgs, baseArgs)
+			cliArgs = append(cliArgs, checkName)
+			return lintExec(ctx, target, r.command, cliArgs...)
+		})
+	}
+
+	// Failures of parsed hooks are reported by name, anything else (e.g. an
+	// unknown hook id) keeps the command's error
+	report := &Report{Runner: r.command}
+	var errs []error
+	for _, result := range results {
+		hooks := parseHookResults(result.output, target.Files)
+		if len(hooks) == 1 && hooks[0].Duration == 0 {
+			hooks[0].Duration = result.duration
+		}
+		report.Hooks = append(report.Hooks, hooks...)
+		if result.err != nil && !hasFailedHook(hooks) {
+			errs = append(errs, result.err)
+		}
+	}
+	if failed := report.Failed(); len(failed) > 0 {
+		errs = append([]error{&FailedError{Hooks: failed}}, errs...)
+	}
+	return report, errors.Join(errs...)
+}
+
+// lefthookRunner runs the commands of lefthook's pre-commit hook on the
+// changed files
+type lefthookRunner struct{}
+
+func (lefthookRunner) Name() string {
+	return "lefthook"
+}
+
+func (r lefthookRunner) Run(target Target, checks []string) (*Report, error) {
+	args := []string{"run", "pre-commit", "--force"}
+	if target.AllFiles {
+		args = append(args, "--all-files")
+	} else {
+		if len(target.Files) == 0 {
+			fmt.Fprintln(target.output(), "Skipping lefthook: no files changed")
+			return &Report{Runner: r.Name()}, nil
+		}
+		for _, file := range target.Files {
+			args = append(args, "--file", file)
+		}
+	}
+	if len(checks) > 0 {
+		args = append(args, "--commands", strings.Join(checks, ","))
+	}
+	cmd := lintExec(target.context(), target, "lefthook", args...)
+	if len(target.Skip) > 0 {
+		cmd.Env = append(cmd.Env, "LEFTHOOK_EXCLUDE="+strings.Join(target.Skip, ","))
+	}
+	return commandReport(r.Name(), target, runLintCommand(target.context(), cmd, target))
+}
+
+// makeRunner runs a make target. The target and check names are passed in
+// the environment, see targetEnv.
+type makeRunner struct {
+	target string
+}
+
+func (r makeRunner) Name() string {
+	return "make " + r.target
+}
+
+func (r makeRunner) Run(target Target, checks []string) (*Report, error) {
+	cmd := lintExec(target.context(), target, "make", r.target)
+	cmd.Env = append(cmd.Env, targetEnv(target, checks)...)
+	return commandReport(r.Name(), target, runLintCommand(target.context(), cmd, target))
+}
+
+// commandRunner runs a configured shell command. The target and check names
+// are passed in the environment, see targetEnv.
+type commandRunner struct {
+	command string
+}
+
+func (r commandRunner) Name() string {
+	return r.command
+}
+
+func (r commandRunner) Run(target Target, checks []string) (*Report, error) {
+	cmd := lintExec(target.context(), target, "sh", "-c", r.command)
+	cmd.Env = append(cmd.Env, targetEnv(target, checks)...)
+	return commandReport(r.Name(), target, runLintCommand(target.context(), cmd, target))
+}
+
+// commandReport reports a runner whose output cannot be split up by hook as
+// a single hook named after the runner
+func commandReport(name string, target Target, result commandResult) (*Report, error) {
+	status := StatusPassed
+	if result.err != nil {
+		status = StatusFailed
+	}
+	output := stripANSI(result.output)
+	hook := HookResult{
+		ID:       name,
+		Name:     name,
+		Status:   status,
+		Duration: result.duration,
+		Files:    mentionedFiles(output, target.Files),
+		Output:   strings.TrimSpace(output),
+	}
+	return &Report{Runner: name, Hooks: []HookResult{hook}}, result.err
+}
+
+// targetEnv describes the target and checks as LINT_* environment variables
+// for runners that cannot take them as arguments
+func targetEnv(target Target, checks []string) []string {
+	allFiles := "0"
+	if target.AllFiles {
+		allFiles = "1"
+	}
+	return []string{
+		"LINT_ALL_FILES=" + allFiles,
+		"LINT_FROM_REF=" + target.FromRef,
+		"LINT_TO_REF=" + target.ToRef,
+		"LINT_FILES=" + strings.Join(target.Files, "\n"),
+		"LINT_CHECKS=" + strings.Join(checks, ","),
+	}
+}
+
+// lintExec returns a command started at the root of the target, with the
+// target's extra environment and hooks to skip. It is stopped when ctx is
+// done.
+func lintExec(ctx context.Context, target Target, name string, args ...string) *exec.Cmd {
+	cmd := commandContext(ctx, name, args...)
+	cmd.Dir = target.Root
+	cmd.Env = append(os.Environ(), target.Env...)
+	if len(target.Skip) > 0 {
+		skip := target.Skip
+		// Hooks skipped by the caller's own SKIP stay skipped
+		if existing := os.Getenv("SKIP"); existing != "" {
+			skip = append([]string{existing}, skip...)
+		}
+		cmd.Env = append(cmd.Env, "SKIP="+strings.Join(skip, ","))
+	}
+	return cmd
+}
+
+// defaultJobs returns how many named checks run at once when Target.Jobs is
+// not set: one per CPU, but one at a time when fixing or when one of the
+// checks is a hook that may rewrite files. pre-commit blames every change of
+// the working tree on the hook that is running, so a fixer running in
+// parallel would fail the other hooks.
+func defaultJobs(target Target, checks []string) int {
+	if target.Fix {
+		return 1
+	}
+	hooks, err := LoadHooks(target.Root)
+	if err != nil {
+		return 1
+	}
+	for _, hook := range hooks {
+		if (slices.Contains(checks, hook.ID) || slices.Contains(checks, hook.Alias)) && hook.modifiesFiles() {
+			slog.Debug("running the named checks one by one", "hook", hook.ID)
+			return 1
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// commandResult is the outcome of one lint command
+type commandResult struct {
+	output   string // combined stdout and stderr
+	duration time.Duration
+	err      error
+}
+
+// runChecks runs the command of each check, at most target.Jobs at a time
+// and each within target.CheckTimeout. Errors name the check. When checks
+// run in parallel their output is buffered and printed one check at a time
+// as they finish. Results are in check order.
+func runChecks(target Target, checks []string, command func(ctx context.Context, checkName string) *exec.Cmd) []commandResult {
+	jobs := max(target.Jobs, 1)
+	run := func(checkName string, printMu *sync.Mutex) commandResult {
+		ctx, cancel := withTimeout(target.context(), target.CheckTimeout, fmt.Sprintf("check %q", checkName))
+		defer cancel()
+		if printMu == nil {
+			return runLintCommand(ctx, command(ctx, checkName), target)
+		}
+		return runBufferedLintCommand(ctx, command(ctx, checkName), target, printMu)
+	}
+
+	results := make([]commandResult, len(checks))
+	if jobs == 1 || len(checks) == 1 {
+		for i, checkName := range checks {
+			results[i] = run(checkName, nil)
+			if results[i].err != nil {
+				results[i].err = fmt.Errorf("check %q failed: %w", checkName, results[i].err)
+			}
+		}
+		return results
+	}
+
+	var (
+		wg      sync.WaitGroup
+		printMu sync.Mutex
+		slots   = make(chan struct{}, jobs)
+	)
+	for i, checkName := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = run(checkName, &printMu)
+			if results[i].err != nil {
+				results[i].err = fmt.Errorf("check %q failed: %w", checkName, results[i].err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runBufferedLintCommand runs cmd with its output buffered. When the target
+// streams, the output is printed in one piece while holding printMu.
+func runBufferedLintCommand(ctx context.Context, cmd *exec.Cmd, target Target, printMu *sync.Mutex) commandResult {
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	result := commandResult{output: string(out), duration: time.Since(start)}
+	if !target.Stream {
+		if err != nil {
+			result.err = commandError(ctx, cmd, string(out))
+		}
+		return result
+	}
+
+	printMu.Lock()
+	fmt.Fprintf(target.output(), "$ %s:\n%s", cmd.String(), out)
+	printMu.Unlock()
+	if err != nil {
+		result.err = commandError(ctx, cmd, "")
+	}
+	return result
+}
+
+// runLintCommand runs cmd, streaming its output if the target streams, and
+// returns the output
+func runLintCommand(ctx context.Context, cmd *exec.Cmd, target Target) commandResult {
+	start := time.Now()
+	var out bytes.Buffer
+	var result commandResult
+	if target.Stream {
+		fmt.Fprintf(target.output(), "$ %s:\n", cmd.String())
+		w := io.MultiWriter(target.output(), &out)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		err := cmd.Run()
+		if err != nil {
+			result.err = commandError(ctx, cmd, "")
+		}
+	} else {
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+		if err != nil {
+			result.err = commandError(ctx, cmd, out.String())
+		}
+	}
+
+	result.output = out.String()
+	result.duration = time.Since(start)
+	return result
+}
+
+// commandError describes a failed command, with its output unless it was
+// streamed. A command stopped by a timeout or an interrupt says so.
+func commandError(ctx context.Context, cmd *exec.Cmd, output string) error {
+	if err := context.Cause(ctx); err != nil {
+		return fmt.Errorf("error running `%s`: %w", cmd.String(), err)
+	}
+	if output == "" {
+		return fmt.Errorf("error running `%s`", cmd.String())
+	}
+	return fmt.Errorf("error running `%s` \n%s", cmd.String(), output)
+}
@@ -0,0 +1,447 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jtamagnan/git-utils/git"
+)
+
+// setUpChangedRepo creates a repository whose branch tracks origin/main and
+// changes a.txt and b.txt on top of it
+func setUpChangedRepo(t *testing.T) *git.TestRepo {
+	testRepo := git.NewTestRepo(t)
+	testRepo.AddCommit("base.txt", "base", "Initial commit")
+	testRepo.AddRemote("origin", "https://github.com/example/repo.git")
+	testRepo.CreateRemoteTrackingBranch("origin", "main")
+	testRepo.SetUpstream("origin", "main")
+	testRepo.AddCommit("a.txt", "a", "Add a")
+	testRepo.CreateFile("b.txt", "b")
+	testRepo.GitExec("add", "b.txt")
+	return testRepo
+}
+
+// TestCommandRunnerEnvironment tests that the configured command receives the
+// refs, changed files and check names
+func TestCommandRunnerEnvironment(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	testRepo.GitExec("config", "lint.command",
+		`printf '%s|%s|%s|%s\n' "$LINT_ALL_FILES" "$LINT_FROM_REF" "$LINT_CHECKS" "$LINT_FILES" > `+envFile)
+
+	testRepo.InDir(func() {
+		err := Lint(ParsedArgs{CheckNames: []string{"vet", "fmt"}})
+		if err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Command did not run: %v", err)
+	}
+	expected := "0|refs/remotes/origin/main|vet,fmt|a.txt\nb.txt\n"
+	if string(content) != expected {
+		t.Errorf("Expected environment %q, got %q", expected, string(content))
+	}
+}
+
+// TestLintFromRef tests that an explicit from-ref replaces the upstream
+// branch, so only the changes since a stacked parent are linted and no
+// upstream is needed
+func TestLintFromRef(t *testing.T) {
+	testRepo := git.NewTestRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.AddCommit("base.txt", "base", "Initial commit")
+	testRepo.AddCommit("parent.txt", "parent", "Parent PR")
+	testRepo.GitExec("branch", "parent")
+	testRepo.AddCommit("child.txt", "child", "Child PR")
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	testRepo.GitExec("config", "lint.command", `printf '%s|%s\n' "$LINT_FROM_REF" "$LINT_FILES" > `+envFile)
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{FromRef: "parent"}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Command did not run: %v", err)
+	}
+	if expected := "parent|child.txt\n"; string(content) != expected {
+		t.Errorf("Expected environment %q, got %q", expected, string(content))
+	}
+}
+
+// TestCommandRunnerFailure tests that a failing command fails the lint run
+// and reports its output
+func TestCommandRunnerFailure(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "lint.command", "echo 'lint error in a.txt'; exit 1")
+
+	testRepo.InDir(func() {
+		err := Lint(ParsedArgs{AllFiles: true})
+		if err == nil {
+			t.Fatal("Expected the failing command to fail the lint run")
+		}
+		if !strings.Contains(err.Error(), "lint error in a.txt") {
+			t.Errorf("Expected the command output in the error, got: %v", err)
+		}
+	})
+}
+
+// TestLintOutput tests that progress and streamed hook output are printed to
+// ParsedArgs.Output
+func TestLintOutput(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "lint.command", "echo 'checked a.txt'")
+
+	testRepo.InDir(func() {
+		var out strings.Builder
+		if err := Lint(ParsedArgs{AllFiles: true, Stream: true, Output: &out}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+		if !strings.Contains(out.String(), "checked a.txt") {
+			t.Errorf("Expected the command output in Output, got %q", out.String())
+		}
+
+		out.Reset()
+		if err := Lint(ParsedArgs{AllFiles: true, Output: &out}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+		if !strings.Contains(out.String(), "Skipping lint checks: they already passed on this tree") {
+			t.Errorf("Expected the cache notice in Output, got %q", out.String())
+		}
+	})
+}
+
+// TestMakeRunner tests that the make target runs with the LINT_* variables
+func TestMakeRunner(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	testRepo.CreateFile("Makefile", "check:\n\t@echo \"$$LINT_FILES\" > "+envFile+"\n")
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("make target did not run: %v", err)
+	}
+	// The Makefile itself is not staged, so only the committed and staged files are listed
+	if string(content) != "a.txt\nb.txt\n" {
+		t.Errorf("Expected the changed files, got %q", string(content))
+	}
+}
+
+// TestLefthookRunnerArgs tests the arguments passed to lefthook with a fake
+// lefthook on PATH
+func TestLefthookRunnerArgs(t *testing.T) {
+	binDir := t.TempDir()
+	argsFile := filepath.Join(binDir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "lefthook"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake lefthook: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name     string
+		target   Target
+		checks   []string
+		expected []string // nil if lefthook should not run
+	}{
+		{
+			name:     "ChangedFiles",
+			target:   Target{Files: []string{"a.txt", "b.txt"}},
+			checks:   []string{"lint", "test"},
+			expected: []string{"run", "pre-commit", "--force", "--file", "a.txt", "--file", "b.txt", "--commands", "lint,test"},
+		},
+		{
+			name:     "AllFiles",
+			target:   Target{AllFiles: true},
+			expected: []string{"run", "pre-commit", "--force", "--all-files"},
+		},
+		{
+			name:   "NoChangedFiles",
+			target: Target{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(argsFile)
+			tt.target.Root = binDir
+
+			if _, err := (lefthookRunner{}).Run(tt.target, tt.checks); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			content, err := os.ReadFile(argsFile)
+			if tt.expected == nil {
+				if err == nil {
+					t.Errorf("Expected lefthook not to run, got args %q", string(content))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lefthook did not run: %v", err)
+			}
+			args := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Errorf("Expected args %q, got %q", tt.expected, args)
+			}
+		})
+	}
+}
+
+// checkErrors joins the errors of results
+func checkErrors(results []commandResult) error {
+	var errs []error
+	for _, result := range results {
+		errs = append(errs, result.err)
+	}
+	return errors.Join(errs...)
+}
+
+// TestRunChecksModifyingHook tests that checks run one by one do not fail
+// each other when a hook modifies files, the way pre-commit does when the
+// files change while a hook runs, and checks run in parallel do
+func TestRunChecksModifyingHook(t *testing.T) {
+	checks := []string{"format", "lint"}
+	tests := []struct {
+		name         string
+		jobs         int
+		expectedLint bool // whether lint is expected to fail
+	}{
+		{"OneByOne", 1, false},
+		{"Parallel", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("unformatted\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			// format rewrites a.go and fails like a pre-commit fixer, lint
+			// fails if a.go changes while it runs
+			scripts := map[string]string{
+				"format": `sleep 0.1; [ "$(cat a.go)" = formatted ] && exit 0; echo formatted > a.go; echo "files were modified by this hook"; exit 1`,
+				"lint":   `before=$(cat a.go); sleep 0.3; [ "$(cat a.go)" = "$before" ] || { echo "files were modified by this hook"; exit 1; }`,
+			}
+			command := func(ctx context.Context, checkName string) *exec.Cmd {
+				cmd := exec.CommandContext(ctx, "sh", "-c", scripts[checkName])
+				cmd.Dir = dir
+				return cmd
+			}
+
+			results := runChecks(Target{Jobs: tt.jobs}, checks, command)
+			if results[0].err == nil {
+				t.Errorf("Expected format to fail after modifying a.go")
+			}
+			if failed := results[1].err != nil; failed != tt.expectedLint {
+				t.Errorf("Expected lint failure %v, got %v", tt.expectedLint, results[1].err)
+			}
+		})
+	}
+}
+
+func TestDefaultJobs(t *testing.T) {
+	root := t.TempDir()
+	config := `repos:
+  - repo: https://github.com/golangci/golangci-lint
+    hooks:
+      - id: golangci-lint
+  - repo: local
+    hooks:
+      - id: gofmt
+      - id: eslint
+        alias: js
+        entry: eslint
+        args: [--fix]
+      - id: govet
+        entry: go vet
+`
+	if err := os.WriteFile(filepath.Join(root, ".pre-commit-config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		root     string
+		fix      bool
+		checks   []string
+		expected int
+	}{
+		{"Linters", root, false, []string{"golangci-lint", "govet"}, runtime.NumCPU()},
+		{"WellKnownFixer", root, false, []string{"golangci-lint", "gofmt"}, 1},
+		{"FixArgumentByAlias", root, false, []string{"js", "govet"}, 1},
+		{"Fix", root, true, []string{"golangci-lint", "govet"}, 1},
+		{"NoPreCommitConfig", t.TempDir(), false, []string{"golangci-lint", "gofmt"}, runtime.NumCPU()},
+	}
+
+	for _, tt := range tests {
+		if jobs := defaultJobs(Target{Root: tt.root, Fix: tt.fix}, tt.checks); jobs != tt.expected {
+			t.Errorf("%s: defaultJobs(%v) = %d, expected %d", tt.name, tt.checks, jobs, tt.expected)
+		}
+	}
+}
+
+// TestRunChecksInParallel tests that checks run concurrently, that their
+// output is not interleaved and that every failure is reported
+func TestRunChecksInParallel(t *testing.T) {
+	checks := []string{"one", "two", "three", "four"}
+	command := func(ctx context.Context, checkName string) *exec.Cmd {
+		script := `echo "$1 start"; sleep 0.5; echo "$1 end"; [ "$1" != two ] && [ "$1" != four ]`
+		return exec.CommandContext(ctx, "sh", "-c", script, "sh", checkName)
+	}
+
+	var buf strings.Builder
+	start := time.Now()
+	err := checkErrors(runChecks(Target{Jobs: len(checks), Stream: true, Output: &buf}, checks, command))
+	elapsed := time.Since(start)
+	out := buf.String()
+
+	if elapsed > 1500*time.Millisecond {
+		t.Errorf("Expected the checks to run in parallel, took %v", elapsed)
+	}
+
+	for _, checkName := range checks {
+		group := checkName + " start\n" + checkName + " end\n"
+		if !strings.Contains(out, group) {
+			t.Errorf("Expected the output of %q to be grouped, got:\n%s", checkName, out)
+		}
+	}
+
+	if err == nil {
+		t.Fatal("Expected the failing checks to be reported")
+	}
+	errMsg := err.Error()
+	if !strings.Contains(errMsg, `check "two" failed`) || !strings.Contains(errMsg, `check "four" failed`) {
+		t.Errorf("Expected both failures to be reported, got: %v", err)
+	}
+	if strings.Contains(errMsg, `"one"`) || strings.Contains(errMsg, `"three"`) {
+		t.Errorf("Expected passing checks not to be reported, got: %v", err)
+	}
+	if strings.Index(errMsg, `"two"`) > strings.Index(errMsg, `"four"`) {
+		t.Errorf("Expected failures in check order, got: %v", err)
+	}
+}
+
+// TestRunChecksJobsLimit tests that no more than jobs checks run at once
+func TestRunChecksJobsLimit(t *testing.T) {
+	dir := t.TempDir()
+	checks := []string{"a", "b", "c", "d", "e", "f"}
+	// Each check records the number of checks running alongside it
+	command := func(ctx context.Context, checkName string) *exec.Cmd {
+		script := `touch "$1.running"; ls *.running | wc -l > "$1.count"; sleep 0.2; rm "$1.running"`
+		cmd := exec.CommandContext(ctx, "sh", "-c", script, "sh", checkName)
+		cmd.Dir = dir
+		return cmd
+	}
+
+	if err := checkErrors(runChecks(Target{Jobs: 2}, checks, command)); err != nil {
+		t.Fatalf("runChecks failed: %v", err)
+	}
+
+	for _, checkName := range checks {
+		content, err := os.ReadFile(filepath.Join(dir, checkName+".count"))
+		if err != nil {
+			t.Fatalf("Check %q did not run: %v", checkName, err)
+		}
+		if count := strings.TrimSpace(string(content)); count != "1" && count != "2" {
+			t.Errorf("Expected at most 2 checks at once, %q saw %s", checkName, count)
+		}
+	}
+}
+
+// TestRunChecksTimeout tests that a check is stopped, along with the
+// processes it started, once the check timeout expires
+func TestRunChecksTimeout(t *testing.T) {
+	checks := []string{"fast", "slow"}
+	command := func(ctx context.Context, checkName string) *exec.Cmd {
+		return commandContext(ctx, "sh", "-c", `[ "$1" = fast ] || sleep 5`, "sh", checkName)
+	}
+
+	start := time.Now()
+	err := checkErrors(runChecks(Target{Jobs: 2, CheckTimeout: 300 * time.Millisecond}, checks, command))
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the slow check to be stopped, took %v", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), `check "slow" timed out after 300ms`) {
+		t.Errorf("Expected the slow check to time out, got: %v", err)
+	}
+	if strings.Contains(err.Error(), `"fast"`) {
+		t.Errorf("Expected the fast check to pass, got: %v", err)
+	}
+}
+
+// TestLintTimeout tests that lint.timeout bounds the whole run
+func TestLintTimeout(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+	testRepo.GitExec("config", "lint.command", "sleep 5")
+	testRepo.GitExec("config", "lint.timeout", "300ms")
+
+	testRepo.InDir(func() {
+		err := Lint(ParsedArgs{})
+		if err == nil || !strings.Contains(err.Error(), "lint run timed out after 300ms") {
+			t.Errorf("Expected the run to time out, got: %v", err)
+		}
+
+		testRepo.GitExec("config", "lint.timeout", "soon")
+		if err := Lint(ParsedArgs{}); err == nil || !strings.Contains(err.Error(), `invalid lint.timeout "soon"`) {
+			t.Errorf("Expected an invalid timeout error, got: %v", err)
+		}
+	})
+}
+
+// TestLintSkipAndEnv tests that skipped hooks are added to the caller's SKIP
+// and that the extra environment from the config reaches the hooks
+func TestLintSkipAndEnv(t *testing.T) {
+	testRepo := setUpChangedRepo(t)
+	defer testRepo.Cleanup()
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	testRepo.CreateFile(".git-review.yaml", "lint:\n  env:\n    FROM_FILE: file\n    OVERRIDDEN: file\n")
+	testRepo.GitExec("config", "lint.command", `printf '%s|%s|%s|%s' "$SKIP" "$FROM_FILE" "$OVERRIDDEN" "$FROM_GIT" > `+envFile)
+	testRepo.GitExec("config", "--add", "lint.env", "OVERRIDDEN=git")
+	testRepo.GitExec("config", "--add", "lint.env", "FROM_GIT=a=b")
+	t.Setenv("SKIP", "mine")
+
+	testRepo.InDir(func() {
+		if err := Lint(ParsedArgs{Skip: []string{"slow-hook", "other"}}); err != nil {
+			t.Fatalf("Lint failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Command did not run: %v", err)
+	}
+	if expected := "mine,slow-hook,other|file|git|a=b"; string(content) != expected {
+		t.Errorf("Expected environment %q, got %q", expected, string(content))
+	}
+}
@@ -1,8 +1,18 @@
 package main
 
 import (
-	lint "github.com/jtamagnan/git-utils/lint/lib"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jtamagnan/git-utils/git"
+	lint "github.com/jtamagnan/git-utils/lint/lib"
+	"github.com/jtamagnan/git-utils/review/lib/logging"
+	"github.com/jtamagnan/git-utils/review/lib/parent"
 
 	"github.com/spf13/cobra"
 )
@@ -17,6 +27,73 @@ func parseArgs(cmd *cobra.Command, args []string) (lint.ParsedArgs, error) {
 	}
 	parsedArgs.AllFiles = allFiles
 
+	jobs, err := cmd.Flags().GetInt("jobs")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.Jobs = jobs
+
+	noCache, err := cmd.Flags().GetBool("no-cache")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.NoCache = noCache
+
+	perCommit, err := cmd.Flags().GetBool("per-commit")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.PerCommit = perCommit
+
+	autofix, err := cmd.Flags().GetBool("autofix")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.Autofix = autofix
+
+	fix, err := cmd.Flags().GetBool("fix")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.Fix = fix
+
+	parsedArgs.Timeout, err = cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.CheckTimeout, err = cmd.Flags().GetDuration("check-timeout")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.Skip, err = cmd.Flags().GetStringSlice("skip")
+	if err != nil {
+		return parsedArgs, err
+	}
+
+	isolated, err := cmd.Flags().GetBool("isolated")
+	if err != nil {
+		return parsedArgs, err
+	}
+	parsedArgs.Isolated = isolated
+
+	parentSpec, err := cmd.Flags().GetString("parent")
+	if err != nil {
+		return parsedArgs, err
+	}
+	from, err := cmd.Flags().GetString("from")
+	if err != nil {
+		return parsedArgs, err
+	}
+	if from != "" {
+		parentSpec = from
+	}
+	if parentSpec != "" {
+		parsedArgs.FromRef, err = resolveFromRef(parentSpec)
+		if err != nil {
+			return parsedArgs, err
+		}
+	}
+
 	// Get check names from positional arguments if provided
 	if len(args) > 0 {
 		parsedArgs.CheckNames = args
@@ -25,12 +102,68 @@ func parseArgs(cmd *cobra.Command, args []string) (lint.ParsedArgs, error) {
 	return parsedArgs, nil
 }
 
+// resolveFromRef resolves --parent the way `git review` does, so a branch
+// name, a PR number or a git ref lint the same changes a review would push
+func resolveFromRef(parentSpec string) (string, error) {
+	repo, err := git.GetRepository()
+	if err != nil {
+		return "", err
+	}
+	upstream, err := repo.Remote()
+	if err != nil {
+		return "", err
+	}
+	upstreamURL, err := repo.GetRemoteURL(upstream)
+	if err != nil {
+		return "", err
+	}
+	repoInfo, err := git.ParseRepositoryInfo(upstreamURL)
+	if err != nil {
+		return "", err
+	}
+	resolvedParent, err := parent.ResolveParent(repo, parentSpec, repoInfo.Owner, repoInfo.Name)
+	if err != nil {
+		return "", err
+	}
+	return resolvedParent.GitRef, nil
+}
+
+// closeLog closes the --log-file, if any
+var closeLog = func() {}
+
+// setupLogging installs the logger configured by --log-level and --log-file.
+// --verbose also enables debug logs, unless a level was given.
+func setupLogging(cmd *cobra.Command) error {
+	level, _ := cmd.Flags().GetString("log-level")
+	logFile, _ := cmd.Flags().GetString("log-file")
+	closeFn, err := logging.Setup(level, logFile)
+	if err != nil {
+		return err
+	}
+	closeLog = closeFn
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose && !cmd.Flags().Changed("log-level") {
+		logging.SetLevel(slog.LevelDebug)
+	}
+	return nil
+}
+
 func runE(cmd *cobra.Command, args []string) error {
 	parsedArgs, err := parseArgs(cmd, args)
 	if err != nil {
 		return err
 	}
 
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(lint.Formats, format) {
+		return fmt.Errorf("invalid --format %q: expected one of %s", format, strings.Join(lint.Formats, ", "))
+	}
+	if format != "text" {
+		return writeReport(os.Stdout, parsedArgs, format)
+	}
+
 	err = lint.Lint(parsedArgs)
 	if err != nil {
 		return err
@@ -38,16 +171,139 @@ func runE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writeReport runs the checks and writes their report to w in the given
+// format. Everything else printed goes to stderr so that w only carries the
+// report. The cache is bypassed, a cached run has no hook results to report.
+func writeReport(w io.Writer, parsedArgs lint.ParsedArgs, format string) error {
+	if parsedArgs.PerCommit {
+		return fmt.Errorf("--format %s cannot be combined with --per-commit", format)
+	}
+	parsedArgs.Stream = false
+	parsedArgs.Detailed = true
+	parsedArgs.Output = os.Stderr
+	parsedArgs.NoCache = true
+
+	report, err := lint.Run(parsedArgs)
+	if report != nil {
+		if writeErr := report.Write(w, format); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
+
+// completeCheckNames completes check names with the hook ids and aliases of
+// the repository's pre-commit config
+func completeCheckNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	hooks, err := lint.RepositoryHooks()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	descriptions := map[string]string{}
+	for _, hook := range hooks {
+		for _, name := range []string{hook.ID, hook.Alias} {
+			if name != "" && descriptions[name] == "" {
+				descriptions[name] = hook.Name
+			}
+		}
+	}
+
+	var completions []cobra.Completion
+	for _, name := range lint.CheckNames(hooks) {
+		if slices.Contains(args, name) || !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(name, descriptions[name]))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listE prints the hooks of the repository's pre-commit config
+func listE(cmd *cobra.Command, args []string) error {
+	hooks, err := lint.RepositoryHooks()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tSTAGES\tFILES")
+	for _, hook := range hooks {
+		id := hook.ID
+		if hook.Alias != "" {
+			id += " (" + hook.Alias + ")"
+		}
+		stages := "all"
+		if len(hook.Stages) > 0 {
+			stages = strings.Join(hook.Stages, ",")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, hook.Name, stages, filePatterns(hook))
+	}
+	return w.Flush()
+}
+
+// filePatterns describes which files a hook runs on
+func filePatterns(hook lint.Hook) string {
+	var patterns []string
+	if hook.Files != "" {
+		patterns = append(patterns, hook.Files)
+	}
+	if len(hook.Types) > 0 {
+		patterns = append(patterns, "types:"+strings.Join(hook.Types, ","))
+	}
+	if len(hook.TypesOr) > 0 {
+		patterns = append(patterns, "types_or:"+strings.Join(hook.TypesOr, ","))
+	}
+	if hook.Exclude != "" {
+		patterns = append(patterns, "exclude:"+hook.Exclude)
+	}
+	if len(patterns) == 0 {
+		return "*"
+	}
+	return strings.Join(patterns, " ")
+}
+
 func generateCommand() *cobra.Command {
 	var rootCmd = &cobra.Command{
-		Use:          "git-lint [check-name...]",
-		Short:        "Run pre-commit checks in this repository.",
-		Long:         "Run pre-commit checks using prek (preferred) or pre-commit. Optionally specify one or more specific check names to run only those checks.",
+		Use:   "git-lint [check-name...]",
+		Short: "Run lint checks in this repository.",
+		Long:  "Run lint checks with pre-commit/prek, lefthook, a make target, the command configured in lint.command or the built-in checks. Optionally specify one or more specific check names to run only those checks.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setupLogging(cmd)
+		},
 		RunE:         runE,
 		SilenceUsage: true,
+		// Check names are positional, subcommands are only looked up by name
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeCheckNames,
 	}
 
+	rootCmd.PersistentFlags().String("log-level", "info", "Log level: "+strings.Join(logging.Levels, ", "))
+	rootCmd.PersistentFlags().String("log-file", "", "Write logs to this file instead of stderr")
+	rootCmd.PersistentFlags().Bool("verbose", false, "Log the git commands run and their timings")
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:          "list",
+		Short:        "List the pre-commit hooks that can be passed as check names.",
+		Args:         cobra.NoArgs,
+		RunE:         listE,
+		SilenceUsage: true,
+	})
+
 	rootCmd.Flags().BoolP("all", "a", false, "Run against all files")
+	rootCmd.Flags().IntP("jobs", "j", 0, "Number of named checks to run in parallel (default: one per CPU, or one by one with --fix or when a check may modify files)")
+	rootCmd.Flags().Bool("no-cache", false, "Run the checks even if they already passed on the same tree")
+	rootCmd.Flags().Bool("per-commit", false, "Lint each commit since the upstream branch on its own in a temporary worktree")
+	rootCmd.Flags().Bool("autofix", false, "Fold changes made by the checks into the commits that last touched those lines and lint again")
+	rootCmd.Flags().Bool("fix", false, "Let the built-in checks fix trailing whitespace, final newlines and gofmt")
+	rootCmd.Flags().Duration("timeout", 0, "Stop the checks after this long, e.g. 10m (default: lint.timeout, or no limit)")
+	rootCmd.Flags().Duration("check-timeout", 0, "Stop each named check after this long (default: lint.check-timeout, or no limit)")
+	rootCmd.Flags().StringSlice("skip", nil, "Hooks to skip, added to SKIP for pre-commit and prek")
+	rootCmd.Flags().Bool("isolated", false, "Lint HEAD in a temporary worktree, ignoring uncommitted changes")
+	rootCmd.Flags().String("parent", "", "Lint the changes since this parent (branch name, PR number, or git ref) instead of the upstream branch")
+	rootCmd.Flags().String("from", "", "Alias for --parent")
+	rootCmd.MarkFlagsMutuallyExclusive("parent", "from")
+	rootCmd.Flags().String("format", "text", "Output format: text streams the checks, json, junit and sarif print a report of every hook")
 
 	return rootCmd
 }
@@ -55,6 +311,7 @@ func generateCommand() *cobra.Command {
 func main() {
 	rootCmd := generateCommand()
 	err := rootCmd.Execute()
+	closeLog()
 	if err != nil {
 		os.Exit(1)
 	}
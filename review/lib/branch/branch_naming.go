@@ -4,10 +4,22 @@ import (
 	"crypto/rand"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/jtamagnan/git-utils/git"
 )
 
+// DefaultNameTemplate is the remote branch name template used when none is configured
+const DefaultNameTemplate = "{user}/{prefix}/{uuid}"
+
+// DefaultPrefix is the value of {prefix} when no branch prefix is configured
+const DefaultPrefix = "pr"
+
+// maxSlugLength limits the length of {slug} so branch names stay readable
+const maxSlugLength = 40
+
 // getUserIdentifier gets the user identifier from git config or environment variable
 func getUserIdentifier() (string, error) {
 	// First try git config
@@ -24,21 +36,154 @@ func getUserIdentifier() (string, error) {
 	return "", fmt.Errorf("no user identifier found: set 'git config review.user-identifier <name>' or ensure USER environment variable is set")
 }
 
-// GenerateUUIDBranchName creates a user-prefixed UUID-based branch name for new PRs
-func GenerateUUIDBranchName() (string, error) {
-	// Get user identifier
-	userID, err := getUserIdentifier()
-	if err != nil {
-		return "", err
-	}
-
-	// Generate a simple UUID-like string for branch naming
+// generateUUID returns a random UUID-like string (8-4-4-4-12 hex characters)
+func generateUUID() (string, error) {
 	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %v", err)
 	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
+}
+
+// NameData holds the values available to branch name templates
+type NameData struct {
+	Prefix  string    // {prefix}, defaults to DefaultPrefix
+	Summary string    // commit summary used for {slug}
+	Ticket  string    // {ticket}, e.g. "ENG-123"
+	Date    time.Time // {date}, formatted as YYYY-MM-DD; defaults to today
+}
+
+// RenderName expands the placeholders {user}, {prefix}, {slug}, {date},
+// {ticket} and {uuid} in nameTemplate and sanitizes the result into a valid
+// ref name. An empty template uses DefaultNameTemplate.
+func RenderName(nameTemplate string, data NameData) (string, error) {
+	if nameTemplate == "" {
+		nameTemplate = DefaultNameTemplate
+	}
+	if data.Prefix == "" {
+		data.Prefix = DefaultPrefix
+	}
+	if data.Date.IsZero() {
+		data.Date = time.Now()
+	}
+
+	replacements := []string{
+		"{prefix}", data.Prefix,
+		"{slug}", Slugify(data.Summary),
+		"{date}", data.Date.Format("2006-01-02"),
+		"{ticket}", strings.TrimPrefix(data.Ticket, "#"),
+	}
+
+	// Only look up the user and generate a UUID if the template needs them
+	if strings.Contains(nameTemplate, "{user}") {
+		userID, err := getUserIdentifier()
+		if err != nil {
+			return "", err
+		}
+		replacements = append(replacements, "{user}", userID)
+	}
+	if strings.Contains(nameTemplate, "{uuid}") {
+		uuid, err := generateUUID()
+		if err != nil {
+			return "", err
+		}
+		replacements = append(replacements, "{uuid}", uuid)
+	}
+
+	name := SanitizeRefName(strings.NewReplacer(replacements...).Replace(nameTemplate))
+	if name == "" {
+		return "", fmt.Errorf("branch name template %q produced an empty branch name", nameTemplate)
+	}
+	return name, nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns a commit summary into a short, lowercase, dash-separated slug
+func Slugify(summary string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(summary), "-"), "-")
+	if len(slug) <= maxSlugLength {
+		return slug
+	}
+
+	// Cut at the last word boundary that fits
+	slug = slug[:maxSlugLength]
+	if i := strings.LastIndex(slug, "-"); i > 0 {
+		slug = slug[:i]
+	}
+	return slug
+}
+
+// invalidRefChars matches characters git does not allow in ref names (see
+// git-check-ref-format), plus whitespace
+var invalidRefChars = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]+`)
+
+var repeatedDashes = regexp.MustCompile(`-{2,}`)
+
+// SanitizeRefName rewrites name into a valid branch name: invalid characters
+// become dashes, and empty components, leading dots, trailing ".lock", ".."
+// and "@{" sequences are removed
+func SanitizeRefName(name string) string {
+	name = invalidRefChars.ReplaceAllString(name, "-")
+	name = strings.ReplaceAll(name, "@{", "-")
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", ".")
+	}
+	name = repeatedDashes.ReplaceAllString(name, "-")
+
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		component = strings.Trim(component, "-.")
+		for strings.HasSuffix(component, ".lock") {
+			component = strings.Trim(strings.TrimSuffix(component, ".lock"), "-.")
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	name = strings.Join(components, "/")
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+// UniqueRemoteName returns name, or name with a numeric suffix, such that no
+// branch of that name exists on the remote
+func UniqueRemoteName(repo *git.Repository, remote, name string) (string, error) {
+	out, err := repo.GitExec("ls-remote", "--heads", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to list branches on %s: %v", remote, err)
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			existing[strings.TrimPrefix(fields[1], "refs/heads/")] = true
+		}
+	}
+
+	candidate := name
+	for i := 2; existing[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	return candidate, nil
+}
+
+// GenerateName renders nameTemplate and makes sure the result does not
+// collide with an existing branch on the remote
+func GenerateName(repo *git.Repository, remote, nameTemplate string, data NameData) (string, error) {
+	name, err := RenderName(nameTemplate, data)
+	if err != nil {
+		return "", err
+	}
+	return UniqueRemoteName(repo, remote, name)
+}
 
-	// Format as: user/pr/UUID (8-4-4-4-12 characters)
-	return fmt.Sprintf("%s/pr/%x-%x-%x-%x-%x",
-		userID, bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
+// GenerateUUIDBranchName creates a user-prefixed UUID-based branch name for new
+// PRs, as <user>/<prefix>/<uuid>. An empty prefix defaults to "pr".
+func GenerateUUIDBranchName(prefix string) (string, error) {
+	return RenderName(DefaultNameTemplate, NameData{Prefix: prefix})
 }
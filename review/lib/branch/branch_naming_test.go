@@ -2,9 +2,13 @@ package branch
 
 import (
 	"os"
+	"os/exec"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/jtamagnan/git-utils/git"
 )
 
 func TestGetUserIdentifier(t *testing.T) {
@@ -51,7 +55,7 @@ func TestGenerateUUIDBranchName(t *testing.T) {
 	// Set a known user for consistent testing
 	_ = os.Setenv("USER", "testuser")
 
-	branch1, err := GenerateUUIDBranchName()
+	branch1, err := GenerateUUIDBranchName("")
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -81,7 +85,7 @@ func TestGenerateUUIDBranchNameWithDifferentUsers(t *testing.T) {
 
 	for _, user := range users {
 		_ = os.Setenv("USER", user)
-		branch, err := GenerateUUIDBranchName()
+		branch, err := GenerateUUIDBranchName("")
 		if err != nil {
 			t.Fatalf("Expected no error for user %s, got: %v", user, err)
 		}
@@ -110,7 +114,7 @@ func TestGenerateUUIDBranchNameUniqueness(t *testing.T) {
 	// Generate multiple UUIDs and verify they're unique
 	generatedUUIDs := make(map[string]bool)
 	for i := 0; i < 1000; i++ {
-		uuid, err := GenerateUUIDBranchName()
+		uuid, err := GenerateUUIDBranchName("")
 		if err != nil {
 			t.Fatalf("Expected no error on iteration %d, got: %v", i, err)
 		}
@@ -130,7 +134,7 @@ func TestGenerateUUIDBranchNameError(t *testing.T) {
 	_ = os.Setenv("USER", "")
 
 	// Should return error when no user identifier is available
-	branch, err := GenerateUUIDBranchName()
+	branch, err := GenerateUUIDBranchName("")
 	if err == nil {
 		t.Errorf("Expected error when no user identifier available, but got branch: %s", branch)
 	}
@@ -143,3 +147,154 @@ func TestGenerateUUIDBranchNameError(t *testing.T) {
 		t.Errorf("Expected helpful error message, got: %v", err)
 	}
 }
+
+func TestGenerateUUIDBranchNameWithPrefix(t *testing.T) {
+	t.Setenv("USER", "testuser")
+
+	branch, err := GenerateUUIDBranchName("feature")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(branch, "testuser/feature/") {
+		t.Errorf("Expected branch name to start with 'testuser/feature/', got: %s", branch)
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	t.Setenv("USER", "jat")
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		data     NameData
+		expected string
+	}{
+		{
+			name:     "prefix, ticket and slug",
+			template: "{prefix}/{ticket}-{slug}",
+			data:     NameData{Prefix: "feature", Summary: "Fix login redirect", Ticket: "ENG-123", Date: date},
+			expected: "feature/ENG-123-fix-login-redirect",
+		},
+		{
+			name:     "missing ticket leaves no stray dashes",
+			template: "{user}/{ticket}-{slug}",
+			data:     NameData{Summary: "Fix login", Date: date},
+			expected: "jat/fix-login",
+		},
+		{
+			name:     "issue references drop the hash",
+			template: "{user}/issue-{ticket}",
+			data:     NameData{Ticket: "#456", Date: date},
+			expected: "jat/issue-456",
+		},
+		{
+			name:     "date and default prefix",
+			template: "{user}/{prefix}/{date}-{slug}",
+			data:     NameData{Summary: "Bump deps", Date: date},
+			expected: "jat/pr/2025-03-14-bump-deps",
+		},
+		{
+			name:     "prefix with trailing slash",
+			template: "{prefix}/{slug}",
+			data:     NameData{Prefix: "hotfix/", Summary: "Patch: CVE~1", Date: date},
+			expected: "hotfix/patch-cve-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := RenderName(tt.template, tt.data)
+			if err != nil {
+				t.Fatalf("RenderName failed: %v", err)
+			}
+			if name != tt.expected {
+				t.Errorf("RenderName(%q) = %q, expected %q", tt.template, name, tt.expected)
+			}
+		})
+	}
+
+	if _, err := RenderName("{ticket}", NameData{}); err == nil {
+		t.Error("Expected error when the template renders to an empty name")
+	}
+}
+
+func TestRenderNameUUID(t *testing.T) {
+	t.Setenv("USER", "jat")
+
+	name, err := RenderName("", NameData{})
+	if err != nil {
+		t.Fatalf("RenderName failed: %v", err)
+	}
+	matched, _ := regexp.MatchString(`^jat/pr/[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, name)
+	if !matched {
+		t.Errorf("Expected default template to produce <user>/pr/<uuid>, got %s", name)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Fix login redirect":   "fix-login-redirect",
+		"  [WIP] Add API v2!!": "wip-add-api-v2",
+		"Refactor the configuration loading code into separate layers": "refactor-the-configuration-loading-code",
+		"": "",
+	}
+
+	for summary, expected := range tests {
+		if slug := Slugify(summary); slug != expected {
+			t.Errorf("Slugify(%q) = %q, expected %q", summary, slug, expected)
+		}
+	}
+}
+
+func TestSanitizeRefName(t *testing.T) {
+	tests := map[string]string{
+		"feature/ok-name":         "feature/ok-name",
+		"feature//double":         "feature/double",
+		"user/.hidden/x.lock":     "user/hidden/x",
+		"a..b":                    "a.b",
+		"bad name: with ~ chars?": "bad-name-with-chars",
+		"ref@{1}":                 "ref-1}",
+		"/leading/trailing/":      "leading/trailing",
+		"@":                       "",
+	}
+
+	for input, expected := range tests {
+		if name := SanitizeRefName(input); name != expected {
+			t.Errorf("SanitizeRefName(%q) = %q, expected %q", input, name, expected)
+		}
+	}
+}
+
+func TestUniqueRemoteName(t *testing.T) {
+	remoteDir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", remoteDir).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create bare remote: %v\n%s", err, out)
+	}
+
+	testRepo := git.NewTestRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.AddCommit("file.txt", "content", "Initial commit")
+	testRepo.GitExec("remote", "add", "origin", remoteDir)
+	testRepo.GitExec("push", "origin", "HEAD:refs/heads/feature/fix-login")
+	testRepo.GitExec("push", "origin", "HEAD:refs/heads/feature/fix-login-2")
+
+	testRepo.InDir(func() {
+		name, err := UniqueRemoteName(testRepo.Repo, "origin", "feature/fix-login")
+		if err != nil {
+			t.Fatalf("UniqueRemoteName failed: %v", err)
+		}
+		if name != "feature/fix-login-3" {
+			t.Errorf("Expected 'feature/fix-login-3', got %q", name)
+		}
+
+		name, err = UniqueRemoteName(testRepo.Repo, "origin", "feature/new")
+		if err != nil {
+			t.Fatalf("UniqueRemoteName failed: %v", err)
+		}
+		if name != "feature/new" {
+			t.Errorf("Expected unused name to be kept, got %q", name)
+		}
+	})
+}
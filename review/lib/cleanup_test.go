@@ -1,6 +1,7 @@
 package review
 
 import (
+	"io"
 	"testing"
 
 	"github.com/jtamagnan/git-utils/git"
@@ -30,7 +31,7 @@ func TestCleanupRemoteBranch(t *testing.T) {
 		remoteBranchName := "user/pr/test-uuid-12345"
 
 		// Test the cleanup function - it should not crash even if the branch doesn't exist
-		cleanupRemoteBranch(testRepo.Repo, "origin", remoteBranchName)
+		cleanupRemoteBranch(io.Discard, testRepo.Repo, "origin", remoteBranchName)
 
 		// The function should complete without error (though it may warn about the non-existent branch)
 		// This test mainly ensures the function doesn't panic and handles errors gracefully
@@ -56,7 +57,7 @@ func TestCleanupRemoteBranchLogging(t *testing.T) {
 		remoteBranchName := "user/pr/fake-branch-for-cleanup-test"
 
 		// This should not crash and should handle the "branch doesn't exist" error gracefully
-		cleanupRemoteBranch(testRepo.Repo, "origin", remoteBranchName)
+		cleanupRemoteBranch(io.Discard, testRepo.Repo, "origin", remoteBranchName)
 
 		// Test passes if no panic occurs
 		t.Log("Cleanup function executed successfully")
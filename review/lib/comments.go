@@ -0,0 +1,148 @@
+package review
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jtamagnan/git-utils/git"
+	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+	"github.com/jtamagnan/git-utils/review/lib/parent"
+	"github.com/jtamagnan/git-utils/review/lib/pr"
+	"github.com/jtamagnan/git-utils/review/lib/threads"
+)
+
+// CommentsParsedArgs represents the parsed command line arguments for the comments command
+type CommentsParsedArgs struct {
+	Parent   string
+	Quickfix bool
+	Resolved bool
+}
+
+// Comments prints the review threads of every PR between the parent branch and HEAD
+func Comments(args CommentsParsedArgs) error {
+	repo, err := git.GetRepository()
+	if err != nil {
+		return err
+	}
+
+	upstream, err := repo.Remote()
+	if err != nil {
+		return fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+	}
+
+	upstreamURL, err := repo.GetRemoteURL(upstream)
+	if err != nil {
+		return err
+	}
+
+	repoInfo, err := git.ParseRepositoryInfo(upstreamURL)
+	if err != nil {
+		return err
+	}
+
+	resolvedParent, err := parent.ResolveParent(repo, args.Parent, repoInfo.Owner, repoInfo.Name)
+	if err != nil {
+		return err
+	}
+
+	commits, err := pr.DetectAllPRs(repo, resolvedParent.GitRef)
+	if err != nil {
+		return err
+	}
+
+	// Collect each PR once, in stack order
+	var prNumbers []int
+	seen := make(map[int]bool)
+	for _, c := range commits {
+		if c.PRNum > 0 && !seen[c.PRNum] {
+			seen[c.PRNum] = true
+			prNumbers = append(prNumbers, c.PRNum)
+		}
+	}
+	if len(prNumbers) == 0 {
+		return fmt.Errorf("no PR URL found in commits between %s and HEAD - run 'git review' first", resolvedParent.GitRef)
+	}
+
+	mapper := threads.NewLineMapper(repo)
+	var entries []threads.Entry
+	for _, prNumber := range prNumbers {
+		prThreads, err := githubapi.GetReviewThreads(repoInfo.Owner, repoInfo.Name, prNumber)
+		if err != nil {
+			return err
+		}
+
+		for _, thread := range prThreads.Threads {
+			if thread.IsResolved && !args.Resolved {
+				continue
+			}
+			entries = append(entries, threads.Entry{
+				PRNumber: prNumber,
+				Position: locateThread(mapper, prThreads, thread),
+				Thread:   thread,
+			})
+		}
+	}
+	threads.SortEntries(entries)
+
+	if args.Quickfix {
+		threads.WriteQuickfix(os.Stdout, entries, relativePathFunc(repo))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No open review threads")
+		return nil
+	}
+	threads.WriteGrouped(os.Stdout, entries)
+	return nil
+}
+
+// locateThread finds where a review thread's line is in the local HEAD,
+// following any commits made since the comment was written
+func locateThread(mapper *threads.LineMapper, prThreads *githubapi.PRReviewThreads, thread githubapi.ReviewThread) threads.Position {
+	commit := prThreads.HeadOID
+	line := thread.Line
+
+	switch {
+	case thread.DiffSide == "LEFT":
+		// Comments on removed lines refer to the base of the PR
+		commit = prThreads.BaseOID
+		if line == 0 {
+			line = thread.OriginalLine
+		}
+	case line == 0 && len(thread.Comments) > 0:
+		// Outdated threads only know their position in the original commit
+		commit = thread.Comments[0].OriginalCommit
+		line = thread.OriginalLine
+	}
+
+	position, err := mapper.Map(commit, thread.Path, line)
+	if err != nil {
+		// Fall back to the position GitHub reported
+		return threads.Position{Path: thread.Path, Line: line}
+	}
+	return position
+}
+
+// relativePathFunc returns a function converting repository-relative paths
+// into paths relative to the current directory
+func relativePathFunc(repo *git.Repository) func(string) string {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return func(path string) string { return path }
+	}
+	root := workTree.Filesystem.Root()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return func(path string) string { return path }
+	}
+
+	return func(path string) string {
+		rel, err := filepath.Rel(cwd, filepath.Join(root, path))
+		if err != nil {
+			return path
+		}
+		return rel
+	}
+}
@@ -1,7 +1,10 @@
 package commit
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -10,8 +13,9 @@ import (
 	"github.com/jtamagnan/git-utils/git"
 )
 
-// UpdateOldestCommitWithPRURL updates the oldest commit message to include the PR URL
-func UpdateOldestCommitWithPRURL(repo *git.Repository, upstreamBranch, prURL string) error {
+// UpdateOldestCommitWithPRURL updates the oldest commit message to include the PR URL,
+// printing what it changed to w
+func UpdateOldestCommitWithPRURL(w io.Writer, repo *git.Repository, upstreamBranch, prURL string) error {
 	// Get all commit summaries to find the oldest one
 	summaries := repo.RefSummaries(upstreamBranch)
 	if len(summaries) == 0 {
@@ -41,14 +45,9 @@ func UpdateOldestCommitWithPRURL(repo *git.Repository, upstreamBranch, prURL str
 		return fmt.Errorf("no commit hashes found")
 	}
 
-	fmt.Printf("DEBUG: found %d commits between %s and HEAD\n", len(commitHashes), upstreamBranch)
-	for i, h := range commitHashes {
-		fmt.Printf("DEBUG:   commit[%d]: %s\n", i, h)
-	}
-
 	// Get the oldest commit hash (first in the list)
 	oldestCommitHash := commitHashes[0]
-	fmt.Printf("DEBUG: oldest commit hash (target): %s\n", oldestCommitHash)
+	slog.Debug("stamping PR URL", "upstream", upstreamBranch, "commits", commitHashes, "target", oldestCommitHash)
 
 	// Get the current commit message
 	currentMessage, err := repo.GitExec("log", "-1", "--pretty=format:%B", oldestCommitHash)
@@ -69,14 +68,14 @@ func UpdateOldestCommitWithPRURL(repo *git.Repository, upstreamBranch, prURL str
 
 	// Check if we actually made a change
 	if updatedMessage == currentMessage {
-		fmt.Println("PR URL already up to date in commit message")
+		fmt.Fprintln(w, "PR URL already up to date in commit message")
 		return nil
 	}
 
 	if strings.Contains(currentMessage, "PR URL:") {
-		fmt.Printf("Replacing existing PR URL with new one: %s\n", prURL)
+		fmt.Fprintf(w, "Replacing existing PR URL with new one: %s\n", prURL)
 	} else {
-		fmt.Printf("Adding PR URL to commit message: %s\n", prURL)
+		fmt.Fprintf(w, "Adding PR URL to commit message: %s\n", prURL)
 	}
 
 	// Update the commit message
@@ -100,8 +99,9 @@ type rewordEntry struct {
 	newMessage string
 }
 
-// UpdateMultipleCommitsWithPRURLs stamps PR URLs on multiple commits in a single rebase pass
-func UpdateMultipleCommitsWithPRURLs(repo *git.Repository, upstreamBranch string, updates []CommitPRURL) error {
+// UpdateMultipleCommitsWithPRURLs stamps PR URLs on multiple commits in a single rebase
+// pass, printing progress to w
+func UpdateMultipleCommitsWithPRURLs(w io.Writer, repo *git.Repository, upstreamBranch string, updates []CommitPRURL) error {
 	if len(updates) == 0 {
 		return nil
 	}
@@ -139,7 +139,7 @@ func UpdateMultipleCommitsWithPRURLs(repo *git.Repository, upstreamBranch string
 	}
 
 	if len(commitsToUpdate) == 0 {
-		fmt.Println("All PR URLs already up to date")
+		fmt.Fprintln(w, "All PR URLs already up to date")
 		return nil
 	}
 
@@ -186,11 +186,11 @@ func updateMultipleCommitMessagesWithRebase(repo *git.Repository, upstreamBranch
 
 		defer func() {
 			if _, restoreErr := repo.GitExec("reset", "--soft", "HEAD~1"); restoreErr != nil {
-				fmt.Printf("Warning: Failed to reset. Manual recovery: git reset --soft HEAD~1; git read-tree %s\n", indexTreeHash)
+				slog.Warn("failed to reset, recover manually with: git reset --soft HEAD~1; git read-tree "+indexTreeHash, "err", restoreErr)
 				return
 			}
 			if _, restoreErr := repo.GitExec("read-tree", indexTreeHash); restoreErr != nil {
-				fmt.Printf("Warning: Failed to restore index. Manual recovery: git read-tree %s\n", indexTreeHash)
+				slog.Warn("failed to restore index, recover manually with: git read-tree "+indexTreeHash, "err", restoreErr)
 			}
 		}()
 	}
@@ -263,7 +263,7 @@ echo $((COUNTER + 1)) > "%s"
 	_, err = repo.GitExec("rebase", "-i", upstreamBranch)
 	if err != nil {
 		if _, abortErr := repo.GitExec("rebase", "--abort"); abortErr != nil {
-			fmt.Printf("Warning: failed to abort rebase: %v\n", abortErr)
+			slog.Warn("failed to abort rebase", "err", abortErr)
 		}
 		return fmt.Errorf("error during interactive rebase: %v", err)
 	}
@@ -280,10 +280,9 @@ func updateCommitMessage(repo *git.Repository, upstreamBranch, commitHash, newMe
 	}
 
 	commitCount := strings.TrimSpace(countOut)
-	fmt.Printf("DEBUG: commit count: %s, strategy: ", commitCount)
 
 	if commitCount == "1" {
-		fmt.Println("amend")
+		slog.Debug("updating commit message", "commits", commitCount, "strategy", "amend")
 		// Single commit: just amend it
 		_, err = repo.GitExec("commit", "--amend", "-m", newMessage)
 		if err != nil {
@@ -293,7 +292,7 @@ func updateCommitMessage(repo *git.Repository, upstreamBranch, commitHash, newMe
 	}
 
 	// Multiple commits: use interactive rebase (much cleaner and preserves uncommitted changes)
-	fmt.Println("rebase")
+	slog.Debug("updating commit message", "commits", commitCount, "strategy", "rebase")
 	return updateCommitMessageWithRebase(repo, upstreamBranch, commitHash, newMessage)
 }
 
@@ -333,13 +332,13 @@ func updateCommitMessageWithRebase(repo *git.Repository, upstreamBranch, commitH
 		defer func() {
 			// Step 1: Reset to the commit before our temporary commit, putting all changes in index
 			if _, restoreErr := repo.GitExec("reset", "--soft", "HEAD~1"); restoreErr != nil {
-				fmt.Printf("Warning: Failed to reset. Manual recovery: git reset --soft HEAD~1; git read-tree %s\n", indexTreeHash)
+				slog.Warn("failed to reset, recover manually with: git reset --soft HEAD~1; git read-tree "+indexTreeHash, "err", restoreErr)
 				return
 			}
 
 			// Step 2: Restore the original index state (this will automatically show correct working directory diff)
 			if _, restoreErr := repo.GitExec("read-tree", indexTreeHash); restoreErr != nil {
-				fmt.Printf("Warning: Failed to restore index. Manual recovery: git read-tree %s\n", indexTreeHash)
+				slog.Warn("failed to restore index, recover manually with: git read-tree "+indexTreeHash, "err", restoreErr)
 				return
 			}
 		}()
@@ -352,7 +351,7 @@ func updateCommitMessageWithRebase(repo *git.Repository, upstreamBranch, commitH
 	}
 	defer func() {
 		if err := os.RemoveAll(tempDir); err != nil {
-			fmt.Printf("Warning: failed to cleanup temp directory: %v\n", err)
+			slog.Warn("failed to clean up temp directory", "dir", tempDir, "err", err)
 		}
 	}()
 
@@ -363,18 +362,13 @@ func updateCommitMessageWithRebase(repo *git.Repository, upstreamBranch, commitH
 		return fmt.Errorf("error getting abbreviated hash for %s: %v", commitHash, err)
 	}
 	abbrevHash = strings.TrimSpace(abbrevHash)
-	fmt.Printf("DEBUG: looking for abbreviated hash %q (full: %s) in rebase todo\n", abbrevHash, commitHash)
+	slog.Debug("rewording commit in rebase todo", "abbrev", abbrevHash, "commit", commitHash)
 
 	// Create a script that will modify the rebase todo list
 	todoEditorScript := filepath.Join(tempDir, "rebase-todo-editor.sh")
 	todoEditorContent := fmt.Sprintf(`#!/bin/bash
 # Auto-generated script to mark specific commit for reword
-# Debug: show the todo list before modification
-echo "DEBUG: rebase todo before:" >&2
-cat "$1" >&2
 sed -i.bak 's/^pick %s /reword %s /' "$1"
-echo "DEBUG: rebase todo after:" >&2
-cat "$1" >&2
 `, abbrevHash, abbrevHash)
 
 	err = os.WriteFile(todoEditorScript, []byte(todoEditorContent), 0755)
@@ -423,19 +417,21 @@ EOF
 	if err != nil {
 		// If rebase fails, try to abort it to leave things in a clean state
 		if _, abortErr := repo.GitExec("rebase", "--abort"); abortErr != nil {
-			fmt.Printf("Warning: failed to abort rebase: %v\n", abortErr)
+			slog.Warn("failed to abort rebase", "err", abortErr)
 		}
 		return fmt.Errorf("error during interactive rebase: %v", err)
 	}
 
-	// Verify the commit message was actually updated by checking the oldest commit
-	newHashes, verifyErr := repo.GitExec("log", fmt.Sprintf("%s..HEAD", upstreamBranch), "--pretty=format:%H", "--reverse")
-	if verifyErr == nil {
-		hashLines := strings.Split(strings.TrimSpace(newHashes), "\n")
-		if len(hashLines) > 0 && hashLines[0] != "" {
-			newMessage, verifyErr := repo.GitExec("log", "-1", "--pretty=format:%B", hashLines[0])
-			if verifyErr == nil {
-				fmt.Printf("DEBUG: oldest commit message after rebase: %q\n", strings.TrimSpace(newMessage))
+	// Log the oldest commit message to verify the rebase
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		newHashes, verifyErr := repo.GitExec("log", fmt.Sprintf("%s..HEAD", upstreamBranch), "--pretty=format:%H", "--reverse")
+		if verifyErr == nil {
+			hashLines := strings.Split(strings.TrimSpace(newHashes), "\n")
+			if len(hashLines) > 0 && hashLines[0] != "" {
+				newMessage, verifyErr := repo.GitExec("log", "-1", "--pretty=format:%B", hashLines[0])
+				if verifyErr == nil {
+					slog.Debug("oldest commit message after rebase", "message", strings.TrimSpace(newMessage))
+				}
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package commit
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -29,7 +30,7 @@ func TestUpdateCommitMessageWithPRURL(t *testing.T) {
 
 		// Test updating the oldest commit with PR URL
 		prURL := "https://github.com/owner/repo/pull/123"
-		err := UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err := UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update commit with PR URL: %v", err)
 		}
@@ -94,7 +95,7 @@ func TestUpdateCommitMessageSingleCommit(t *testing.T) {
 
 		// Test updating the single commit with PR URL
 		prURL := "https://github.com/owner/repo/pull/456"
-		err := UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err := UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update single commit with PR URL: %v", err)
 		}
@@ -140,13 +141,13 @@ func TestUpdateCommitMessageNoDuplicates(t *testing.T) {
 		prURL := "https://github.com/owner/repo/pull/789"
 
 		// Update once
-		err := UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err := UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update commit with PR URL: %v", err)
 		}
 
 		// Try to update again - should skip due to duplicate detection
-		err = UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err = UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed on second update attempt: %v", err)
 		}
@@ -239,7 +240,7 @@ func TestUncommittedChangesPreservation(t *testing.T) {
 
 		// Now test updating the commit message (this should preserve uncommitted changes)
 		prURL := "https://github.com/owner/repo/pull/123"
-		err = UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err = UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update commit with PR URL: %v", err)
 		}
@@ -331,7 +332,7 @@ func TestUpdateCommitMessageWithLongAbbrev(t *testing.T) {
 
 		// Update the oldest commit with PR URL
 		prURL := "https://github.com/owner/repo/pull/999"
-		err := UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err := UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update commit with PR URL (long abbrev): %v", err)
 		}
@@ -462,7 +463,7 @@ func TestStagedChangesPreservation(t *testing.T) {
 
 		// Now test updating the commit message (this should preserve all staging states)
 		prURL := "https://github.com/owner/repo/pull/123"
-		err = UpdateOldestCommitWithPRURL(testRepo.Repo, "main", prURL)
+		err = UpdateOldestCommitWithPRURL(io.Discard, testRepo.Repo, "main", prURL)
 		if err != nil {
 			t.Fatalf("Failed to update commit with PR URL: %v", err)
 		}
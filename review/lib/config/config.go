@@ -2,10 +2,13 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"path/filepath"
 	"strings"
 
 	"github.com/jtamagnan/git-utils/git"
 	review "github.com/jtamagnan/git-utils/review/lib"
+	"github.com/jtamagnan/git-utils/review/lib/hooks"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -131,6 +134,125 @@ var flagConfigs = []FlagConfig{
 		Default:     CommaString{},
 		Description: "Comma-separated list of reviewers to request for the PR (e.g., 'alice,bob')",
 	},
+	{
+		Name:        "team-reviewers",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of teams to request reviews from, as org/team-slug (e.g., 'acme/backend')",
+	},
+	{
+		Name:        "assignee",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of users to assign to the PR, '@me' for yourself (e.g., '@me,alice')",
+	},
+	{
+		Name:        "milestone",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Title of the milestone to set on the PR",
+	},
+	{
+		Name:        "project",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Title of the GitHub project (Projects v2) to add the PR to",
+	},
+	{
+		Name:        "ticket-trackers",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated ticket trackers as PREFIX=URL, where the URL may use {key} and {id} (e.g., 'ENG=https://linear.app/acme/issue/{key}')",
+	},
+	{
+		Name:        "ticket-title-prefix",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Prefix the PR title with the detected ticket keys",
+	},
+	{
+		Name:        "ticket-required",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "What to do when no ticket is found: 'off', 'warn' or 'error'",
+	},
+	{
+		Name:        "branch-name",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Template for new remote branch names using {user}, {prefix}, {slug}, {date}, {ticket} and {uuid} (default '{user}/{prefix}/{uuid}')",
+	},
+	{
+		Name:        "branch-prefix",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Value of {prefix} in the branch name template (default 'pr')",
+	},
+	{
+		Name:        "template",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Path to the PR description template, relative to the repository root. If not specified, uses the GitHub PR template",
+	},
+	{
+		Name:        "lint-checks",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of pre-commit checks to run before pushing. If not specified, runs all checks",
+	},
+	{
+		Name:        "lint-skip",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of pre-commit hooks to skip before pushing, passed to the hooks as SKIP",
+	},
+	{
+		Name:        "autofix",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "When pre-commit checks modify files, fold the changes into the commits that last touched those lines and run the checks again",
+	},
+	{
+		Name:        "isolated-lint",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Run pre-commit checks on HEAD in a temporary worktree, so uncommitted changes do not affect the result",
+	},
+	{
+		Name:        "pre-push-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run before pushing a branch, a non-zero exit aborts",
+	},
+	{
+		Name:        "post-create-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run after a pull request is created",
+	},
+	{
+		Name:        "post-update-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run after an existing pull request is updated",
+	},
 	{
 		Name:        "verbose",
 		Shorthand:   "",
@@ -145,6 +267,20 @@ var flagConfigs = []FlagConfig{
 		Default:     false,
 		Description: "Enable automerge on newly created pull requests",
 	},
+	{
+		Name:        "merge-method",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "merge",
+		Description: "Merge method used for auto-merge: 'merge', 'squash' or 'rebase'",
+	},
+	{
+		Name:        "profile",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Name of a profile from the user config file to apply (e.g., 'hotfix')",
+	},
 	{
 		Name:        "parent",
 		Shorthand:   "p",
@@ -159,22 +295,21 @@ func splitAndTrim(value string) []string {
 	return ParseCommaString(value).ToStringSlice()
 }
 
-// getGitConfigList reads a git config value, splits it by commas, and calls the handler if non-empty
-func getGitConfigList(key string, handler func([]string)) {
-	if value, err := git.GetConfig(key); err == nil && value != "" {
-		handler(splitAndTrim(value))
-	}
-}
-
-// getGitConfigString reads a git config value and calls the handler if non-empty
-func getGitConfigString(key string, handler func(string)) {
-	if value, err := git.GetConfig(key); err == nil && value != "" {
-		handler(value)
+// parseGitBool parses a boolean the way git does (true/yes/on/1, false/no/off/0)
+func parseGitBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true, true
+	case "false", "no", "off", "0":
+		return false, true
 	}
+	return false, false
 }
 
 // InitConfig sets up Viper configuration with defaults, environment variables, git config, and config files
 func InitConfig() {
+	loadedLayers = nil
+
 	// Set defaults from flag configurations
 	for _, flag := range flagConfigs {
 		if flag.Type == "commastring" {
@@ -190,50 +325,134 @@ func InitConfig() {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
-	// Load git config settings (project-specific) BEFORE user config files
-	// This ensures git config takes precedence over user preferences
+	ctx := currentRepoContext()
+
+	// Read the user-level config file first, it has the lowest precedence
+	// after the defaults. Its "repos" entries matching this repository
+	// override the general user settings.
+	var profiles interface{}
+	if path := userConfigFile(); path != "" {
+		userConfig, err := readConfigFile(path)
+		if err != nil {
+			slog.Warn("failed to read user config file", "err", err)
+		} else {
+			slog.Debug("using config file", "path", path)
+			applyLayer(Layer{Name: "user", Source: path, Values: settingValues(userConfig)})
+			applyRepoOverrides(userConfig.Get("repos"), ctx.FullName, path)
+			profiles = userConfig.Get("profiles")
+		}
+	}
+
+	// The checked-in project config file overrides user preferences for the
+	// few project-level settings it allows
+	if root, err := repositoryRoot(); err == nil {
+		projectConfig, err := LoadProjectConfig(root)
+		if err != nil {
+			slog.Warn("failed to read project config file", "err", err)
+		} else {
+			applyLayer(Layer{Name: "project", Source: filepath.Join(root, ProjectConfigFile), Values: projectConfig})
+		}
+	}
+
+	// Git config (local or global) overrides both config files. Environment
+	// variables and command-line flags still take precedence over all of them.
 	loadGitConfig()
 
-	// Set up global config file locations (user-level only)
-	viper.SetConfigName("git-review")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("$HOME/.config") // ~/.config/git-review.yaml
-	viper.AddConfigPath("$HOME")         // ~/.git-review.yaml
+	// A profile is chosen explicitly or by matching this checkout, so it
+	// overrides the config files and git config
+	applyProfile(profiles, ctx)
+}
 
-	// Try to read user-level config file (loaded after git config, so user config has lower precedence)
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
+// repositoryRoot returns the root of the working tree of the current repository
+func repositoryRoot() (string, error) {
+	repo, err := git.GetRepository()
+	if err != nil {
+		return "", err
 	}
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return workTree.Filesystem.Root(), nil
+}
 
-	// Note: We intentionally do NOT read project-level config files for
-	// behavioral settings like open-browser, draft, no-verify, etc.
-	// These should remain user preferences, not project settings.
+// gitConfigAliases are older git config keys still read for some settings.
+// The review.<setting> key takes precedence when both are set.
+var gitConfigAliases = map[string]string{
+	"reviewers": "review.default-reviewers",
+	"labels":    "review.project-labels",
 }
 
-// loadGitConfig reads git config values and sets them in viper
-// This is called from InitConfig to ensure proper precedence order
+// gitConfigOnlyKeys are review.* git config keys that are not settings
+var gitConfigOnlyKeys = map[string]bool{
+	"review.user-identifier": true,
+}
+
+// gitConfigKey returns the git config key for a setting
+func gitConfigKey(setting string) string {
+	return "review." + setting
+}
+
+// loadGitConfig reads every setting from git config as review.<setting> into
+// a config layer above the user and project config files. Git resolves
+// includes, so settings can vary by directory with includeIf.
 func loadGitConfig() {
-	// Default reviewers for this project
-	getGitConfigList("review.default-reviewers", func(reviewers []string) {
-		viper.Set("reviewers", reviewers)
-	})
+	entries, err := git.GetConfigRegexp(`^review\.`)
+	if err != nil {
+		return
+	}
+
+	layer := Layer{
+		Name:       "git",
+		Source:     "git config",
+		Values:     make(map[string]interface{}),
+		KeySources: make(map[string]string),
+	}
+
+	for _, setting := range settingNames() {
+		key := gitConfigKey(setting)
+		value, ok := entries[key]
+		if !ok {
+			if alias, hasAlias := gitConfigAliases[setting]; hasAlias {
+				key = alias
+				value, ok = entries[alias]
+			}
+		}
+		if !ok {
+			continue
+		}
 
-	// Project-specific labels
-	getGitConfigList("review.project-labels", func(projectLabels []string) {
-		viper.Set("labels", projectLabels)
-	})
+		switch settingType(setting) {
+		case "commastring":
+			layer.Values[setting] = splitAndTrim(value)
+		case "bool":
+			b, ok := parseGitBool(value)
+			if !ok {
+				slog.Warn("ignoring git config value: expected a boolean", "key", key, "value", value)
+				continue
+			}
+			layer.Values[setting] = b
+		default:
+			layer.Values[setting] = value
+		}
+		layer.KeySources[setting] = key
+	}
 
-	// Custom branch prefix for this project
-	getGitConfigString("review.branch-prefix", func(branchPrefix string) {
-		viper.Set("project.branch-prefix", branchPrefix)
-	})
+	applyLayer(layer)
 }
 
-// LoadProjectConfig is now deprecated - git config is loaded in InitConfig for proper precedence
-// This function is kept for backward compatibility but does nothing
-func LoadProjectConfig() {
-	// Git config is now loaded in InitConfig() to ensure proper precedence order
-	// where git config (project-specific) takes precedence over user config files
+// parseCommaStringFlags parses lists (labels, reviewers, ...) that were provided
+// as comma-separated strings on the command line
+func parseCommaStringFlags(cmd *cobra.Command, configs []FlagConfig) {
+	for _, flag := range configs {
+		if flag.Type != "commastring" || !cmd.Flags().Changed(flag.Name) {
+			continue
+		}
+		value := viper.GetString(flag.Name)
+		if value != "" {
+			viper.Set(flag.Name, ParseCommaString(value).ToStringSlice())
+		}
+	}
 }
 
 // ParseArgs converts Viper configuration and command-line flags into ParsedArgs
@@ -241,29 +460,17 @@ func ParseArgs(cmd *cobra.Command, _ []string) (review.ParsedArgs, error) {
 	// Viper automatically handles the precedence:
 	// 1. Command-line flags (highest)
 	// 2. Environment variables
-	// 3. Git config (project-specific settings)
-	// 4. User config files
-	// 5. Defaults (lowest)
-
-	// Handle CommaString parsing for labels
-	// If labels was provided as a comma-separated string via command line, parse it
-	if cmd.Flags().Changed("labels") {
-		labelStr := viper.GetString("labels")
-		if labelStr != "" {
-			labels := ParseCommaString(labelStr).ToStringSlice()
-			viper.Set("labels", labels)
-		}
+	// 3. Profile (--profile, REVIEW_PROFILE, or matched by branch/remote)
+	// 4. Git config
+	// 5. Project config file (.git-review.yaml)
+	// 6. User config files
+	// 7. Defaults (lowest)
+
+	if err := checkProfile(); err != nil {
+		return review.ParsedArgs{}, err
 	}
 
-	// Handle CommaString parsing for reviewers
-	// If reviewers was provided as a comma-separated string via command line, parse it
-	if cmd.Flags().Changed("reviewers") {
-		reviewerStr := viper.GetString("reviewers")
-		if reviewerStr != "" {
-			reviewers := ParseCommaString(reviewerStr).ToStringSlice()
-			viper.Set("reviewers", reviewers)
-		}
-	}
+	parseCommaStringFlags(cmd, flagConfigs)
 
 	// Use flag names from configuration to get values
 	parsedArgs := review.ParsedArgs{
@@ -273,13 +480,60 @@ func ParseArgs(cmd *cobra.Command, _ []string) (review.ParsedArgs, error) {
 		AutoMerge:   viper.GetBool("auto-merge"),
 		Labels:      viper.GetStringSlice("labels"),
 		Reviewers:   viper.GetStringSlice("reviewers"),
+		Assignees:   viper.GetStringSlice("assignee"),
+		Milestone:   viper.GetString("milestone"),
+		Project:     viper.GetString("project"),
 		Verbose:     viper.GetBool("verbose"),
 		Parent:      viper.GetString("parent"),
+
+		TeamReviewers: viper.GetStringSlice("team-reviewers"),
+		BranchName:    viper.GetString("branch-name"),
+		BranchPrefix:  viper.GetString("branch-prefix"),
+		Template:      viper.GetString("template"),
+		MergeMethod:   viper.GetString("merge-method"),
+		LintChecks:    viper.GetStringSlice("lint-checks"),
+		LintSkip:      viper.GetStringSlice("lint-skip"),
+		Autofix:       viper.GetBool("autofix"),
+		IsolatedLint:  viper.GetBool("isolated-lint"),
+		Hooks:         parseHooks(),
+
+		TicketTrackers:    viper.GetStringSlice("ticket-trackers"),
+		TicketTitlePrefix: viper.GetBool("ticket-title-prefix"),
+		TicketRequired:    viper.GetString("ticket-required"),
+	}
+
+	if err := validateMergeMethod(parsedArgs.MergeMethod); err != nil {
+		return parsedArgs, err
 	}
 
 	return parsedArgs, nil
 }
 
+// Verbose reports whether verbose output is enabled
+func Verbose() bool {
+	return viper.GetBool("verbose")
+}
+
+// parseHooks reads the configured hook commands
+func parseHooks() hooks.Hooks {
+	configured := hooks.Hooks{}
+	for _, event := range hooks.Events {
+		if command := viper.GetString(hooks.Setting(event)); command != "" {
+			configured[event] = command
+		}
+	}
+	return configured
+}
+
+// validateMergeMethod checks that the merge method is one GitHub supports
+func validateMergeMethod(mergeMethod string) error {
+	switch mergeMethod {
+	case "merge", "squash", "rebase":
+		return nil
+	}
+	return fmt.Errorf("invalid merge-method %q: expected merge, squash or rebase", mergeMethod)
+}
+
 // stackFlagConfigs defines flags specific to the stack subcommand
 var stackFlagConfigs = []FlagConfig{
 	{
@@ -303,6 +557,139 @@ var stackFlagConfigs = []FlagConfig{
 		Default:     false,
 		Description: "Show verbose output including pre-commit check output in real-time",
 	},
+	{
+		Name:        "branch-name",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Template for new remote branch names using {user}, {prefix}, {slug}, {date}, {ticket} and {uuid} (default '{user}/{prefix}/{uuid}')",
+	},
+	{
+		Name:        "branch-prefix",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Value of {prefix} in the branch name template (default 'pr')",
+	},
+	{
+		Name:        "template",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Path to the PR description template, relative to the repository root. If not specified, uses the GitHub PR template",
+	},
+	{
+		Name:        "lint-checks",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of pre-commit checks to run before pushing. If not specified, runs all checks",
+	},
+	{
+		Name:        "lint-skip",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of pre-commit hooks to skip before pushing, passed to the hooks as SKIP",
+	},
+	{
+		Name:        "autofix",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "When pre-commit checks modify files, fold the changes into the commits that last touched those lines and run the checks again",
+	},
+	{
+		Name:        "isolated-lint",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Run pre-commit checks on HEAD in a temporary worktree, so uncommitted changes do not affect the result",
+	},
+	{
+		Name:        "pre-push-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run before pushing a branch, a non-zero exit aborts",
+	},
+	{
+		Name:        "post-create-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run after a pull request is created",
+	},
+	{
+		Name:        "post-update-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run after an existing pull request is updated",
+	},
+	{
+		Name:        "per-commit",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Lint each PR of the stack on its own in a temporary worktree, so every PR passes the checks",
+	},
+	{
+		Name:        "post-stack-hook",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Shell command run after the whole stack is created or updated",
+	},
+	{
+		Name:        "ticket-trackers",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated ticket trackers as PREFIX=URL, where the URL may use {key} and {id} (e.g., 'ENG=https://linear.app/acme/issue/{key}')",
+	},
+	{
+		Name:        "ticket-title-prefix",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Prefix the PR title with the detected ticket keys",
+	},
+	{
+		Name:        "ticket-required",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "What to do when no ticket is found: 'off', 'warn' or 'error'",
+	},
+	{
+		Name:        "assignee",
+		Shorthand:   "",
+		Type:        "commastring",
+		Default:     CommaString{},
+		Description: "Comma-separated list of users to assign to every PR of the stack, '@me' for yourself (e.g., '@me,alice')",
+	},
+	{
+		Name:        "milestone",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Title of the milestone to set on every PR of the stack",
+	},
+	{
+		Name:        "project",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Title of the GitHub project (Projects v2) to add every PR of the stack to",
+	},
+	{
+		Name:        "profile",
+		Shorthand:   "",
+		Type:        "string",
+		Default:     "",
+		Description: "Name of a profile from the user config file to apply (e.g., 'hotfix')",
+	},
 	{
 		Name:        "parent",
 		Shorthand:   "p",
@@ -314,31 +701,116 @@ var stackFlagConfigs = []FlagConfig{
 
 // ParseStackArgs converts flags into StackParsedArgs
 func ParseStackArgs(cmd *cobra.Command, _ []string) (review.StackParsedArgs, error) {
+	if err := checkProfile(); err != nil {
+		return review.StackParsedArgs{}, err
+	}
+
+	parseCommaStringFlags(cmd, stackFlagConfigs)
+
 	parsedArgs := review.StackParsedArgs{
 		NoVerify:    viper.GetBool("no-verify"),
 		OpenBrowser: viper.GetBool("open-browser"),
 		Verbose:     viper.GetBool("verbose"),
 		Parent:      viper.GetString("parent"),
+
+		BranchName:   viper.GetString("branch-name"),
+		BranchPrefix: viper.GetString("branch-prefix"),
+		Template:     viper.GetString("template"),
+		LintChecks:   viper.GetStringSlice("lint-checks"),
+		LintSkip:     viper.GetStringSlice("lint-skip"),
+		PerCommit:    viper.GetBool("per-commit"),
+		Autofix:      viper.GetBool("autofix"),
+		IsolatedLint: viper.GetBool("isolated-lint"),
+		Hooks:        parseHooks(),
+
+		TicketTrackers:    viper.GetStringSlice("ticket-trackers"),
+		TicketTitlePrefix: viper.GetBool("ticket-title-prefix"),
+		TicketRequired:    viper.GetString("ticket-required"),
+
+		Assignees: viper.GetStringSlice("assignee"),
+		Milestone: viper.GetString("milestone"),
+		Project:   viper.GetString("project"),
 	}
 	return parsedArgs, nil
 }
 
+// commentsFlagConfigs defines flags specific to the comments subcommand
+var commentsFlagConfigs = []FlagConfig{
+	{
+		Name:        "parent",
+		Shorthand:   "p",
+		Type:        "string",
+		Default:     "",
+		Description: "Parent branch the PRs are based on (branch name, PR number, or git ref). If not specified, uses upstream default branch",
+	},
+	{
+		Name:        "quickfix",
+		Shorthand:   "q",
+		Type:        "bool",
+		Default:     false,
+		Description: "Print comments as 'file:line: message' for the vim/emacs quickfix list",
+	},
+	{
+		Name:        "resolved",
+		Shorthand:   "",
+		Type:        "bool",
+		Default:     false,
+		Description: "Include resolved review threads",
+	},
+}
+
+// ParseCommentsArgs converts flags into CommentsParsedArgs
+func ParseCommentsArgs(cmd *cobra.Command, _ []string) (review.CommentsParsedArgs, error) {
+	parsedArgs := review.CommentsParsedArgs{
+		Parent:   viper.GetString("parent"),
+		Quickfix: viper.GetBool("quickfix"),
+		Resolved: viper.GetBool("resolved"),
+	}
+	return parsedArgs, nil
+}
+
+// statusFlagConfigs defines flags specific to the status subcommand
+var statusFlagConfigs = []FlagConfig{
+	{
+		Name:        "parent",
+		Shorthand:   "p",
+		Type:        "string",
+		Default:     "",
+		Description: "Parent branch the PRs are based on (branch name, PR number, or git ref). If not specified, uses upstream default branch",
+	},
+}
+
+// ParseStatusArgs converts flags into StatusParsedArgs
+func ParseStatusArgs(cmd *cobra.Command, _ []string) (review.StatusParsedArgs, error) {
+	parsedArgs := review.StatusParsedArgs{
+		Parent: viper.GetString("parent"),
+	}
+	return parsedArgs, nil
+}
+
+// SetupStatusFlags defines and binds command-line flags for the status subcommand
+func SetupStatusFlags(cmd *cobra.Command) {
+	setupFlagConfigs(cmd, statusFlagConfigs)
+}
+
+// SetupCommentsFlags defines and binds command-line flags for the comments subcommand
+func SetupCommentsFlags(cmd *cobra.Command) {
+	setupFlagConfigs(cmd, commentsFlagConfigs)
+}
+
 // SetupStackFlags defines and binds command-line flags for the stack subcommand
 func SetupStackFlags(cmd *cobra.Command) {
-	for _, flag := range stackFlagConfigs {
-		switch flag.Type {
-		case "bool":
-			cmd.Flags().BoolP(flag.Name, flag.Shorthand, flag.Default.(bool), flag.Description)
-		case "string":
-			cmd.Flags().StringP(flag.Name, flag.Shorthand, flag.Default.(string), flag.Description)
-		}
-		_ = viper.BindPFlag(flag.Name, cmd.Flags().Lookup(flag.Name))
-	}
+	setupFlagConfigs(cmd, stackFlagConfigs)
 }
 
 // SetupFlags defines and binds command-line flags to Viper using the flag configurations
 func SetupFlags(cmd *cobra.Command) {
-	for _, flag := range flagConfigs {
+	setupFlagConfigs(cmd, flagConfigs)
+}
+
+// setupFlagConfigs defines the given flags on cmd and binds them to Viper
+func setupFlagConfigs(cmd *cobra.Command, configs []FlagConfig) {
+	for _, flag := range configs {
 		switch flag.Type {
 		case "bool":
 			cmd.Flags().BoolP(flag.Name, flag.Shorthand, flag.Default.(bool), flag.Description)
@@ -354,3 +826,10 @@ func SetupFlags(cmd *cobra.Command) {
 		_ = viper.BindPFlag(flag.Name, cmd.Flags().Lookup(flag.Name))
 	}
 }
+
+// BindFlags re-binds the flags of the command being executed to Viper.
+// Subcommands share flag names (e.g. "parent"), and Viper only keeps the
+// most recent binding for a key, so this must run once the command is known.
+func BindFlags(cmd *cobra.Command) {
+	_ = viper.BindPFlags(cmd.Flags())
+}
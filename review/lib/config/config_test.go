@@ -7,6 +7,7 @@ import (
 
 	"github.com/jtamagnan/git-utils/git"
 	review "github.com/jtamagnan/git-utils/review/lib"
+	"github.com/jtamagnan/git-utils/review/lib/hooks"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
@@ -222,7 +223,7 @@ func TestCommandFlags(t *testing.T) {
 	SetupFlags(cmd)
 
 	// Test that all expected flags are present
-	expectedFlags := []string{"no-verify", "open-browser", "draft", "labels", "reviewers"}
+	expectedFlags := []string{"no-verify", "open-browser", "draft", "labels", "reviewers", "assignee", "milestone", "project", "team-reviewers", "branch-prefix", "template", "merge-method", "lint-checks", "branch-name", "pre-push-hook", "post-create-hook", "post-update-hook"}
 
 	for _, flagName := range expectedFlags {
 		flag := cmd.Flags().Lookup(flagName)
@@ -386,6 +387,131 @@ func TestReviewersParsing(t *testing.T) {
 	}
 }
 
+func TestAssigneeMilestoneProjectParsing(t *testing.T) {
+	viper.Reset()
+	InitConfig()
+
+	cmd := &cobra.Command{Use: "test"}
+	SetupFlags(cmd)
+
+	args := []string{"--assignee", "@me, alice", "--milestone", "v1.2", "--project", "Team Board"}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	parsedArgs, err := ParseArgs(cmd, []string{})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsedArgs.Assignees, []string{"@me", "alice"}) {
+		t.Errorf("Expected assignees [@me alice], got %v", parsedArgs.Assignees)
+	}
+	if parsedArgs.Milestone != "v1.2" {
+		t.Errorf("Expected milestone 'v1.2', got %q", parsedArgs.Milestone)
+	}
+	if parsedArgs.Project != "Team Board" {
+		t.Errorf("Expected project 'Team Board', got %q", parsedArgs.Project)
+	}
+}
+
+func TestAssigneeFromEnvironment(t *testing.T) {
+	viper.Reset()
+	t.Setenv("REVIEW_ASSIGNEE", "@me")
+	t.Setenv("REVIEW_MILESTONE", "Sprint 4")
+	InitConfig()
+
+	cmd := &cobra.Command{Use: "test"}
+	SetupFlags(cmd)
+
+	parsedArgs, err := ParseArgs(cmd, []string{})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsedArgs.Assignees, []string{"@me"}) {
+		t.Errorf("Expected assignees [@me] from env var, got %v", parsedArgs.Assignees)
+	}
+	if parsedArgs.Milestone != "Sprint 4" {
+		t.Errorf("Expected milestone 'Sprint 4' from env var, got %q", parsedArgs.Milestone)
+	}
+}
+
+func TestParseGitBool(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		ok       bool
+	}{
+		{"true", true, true},
+		{"Yes", true, true},
+		{"on", true, true},
+		{"1", true, true},
+		{"false", false, true},
+		{"no", false, true},
+		{"OFF", false, true},
+		{"0", false, true},
+		{"maybe", false, false},
+	}
+
+	for _, tt := range tests {
+		value, ok := parseGitBool(tt.input)
+		if value != tt.expected || ok != tt.ok {
+			t.Errorf("parseGitBool(%q) = (%v, %v), expected (%v, %v)", tt.input, value, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestStackTicketFlags(t *testing.T) {
+	viper.Reset()
+	InitConfig()
+
+	cmd := &cobra.Command{Use: "stack"}
+	SetupStackFlags(cmd)
+
+	args := []string{"--ticket-trackers", "ENG=https://linear.app/acme/issue/{key}", "--ticket-required", "error", "--ticket-title-prefix"}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	parsedArgs, err := ParseStackArgs(cmd, []string{})
+	if err != nil {
+		t.Fatalf("ParseStackArgs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsedArgs.TicketTrackers, []string{"ENG=https://linear.app/acme/issue/{key}"}) {
+		t.Errorf("Unexpected ticket trackers: %v", parsedArgs.TicketTrackers)
+	}
+	if parsedArgs.TicketRequired != "error" || !parsedArgs.TicketTitlePrefix {
+		t.Errorf("Unexpected ticket settings: required=%q titlePrefix=%v", parsedArgs.TicketRequired, parsedArgs.TicketTitlePrefix)
+	}
+}
+
+func TestStackAssigneeMilestoneProject(t *testing.T) {
+	viper.Reset()
+	InitConfig()
+
+	cmd := &cobra.Command{Use: "stack"}
+	SetupStackFlags(cmd)
+
+	args := []string{"--assignee", "@me,alice", "--milestone", "v1.2", "--project", "Team Board"}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	parsedArgs, err := ParseStackArgs(cmd, []string{})
+	if err != nil {
+		t.Fatalf("ParseStackArgs failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsedArgs.Assignees, []string{"@me", "alice"}) {
+		t.Errorf("Expected assignees [@me alice], got %v", parsedArgs.Assignees)
+	}
+	if parsedArgs.Milestone != "v1.2" || parsedArgs.Project != "Team Board" {
+		t.Errorf("Unexpected milestone %q or project %q", parsedArgs.Milestone, parsedArgs.Project)
+	}
+}
+
 func TestGitConfigKebabCaseKeys(t *testing.T) {
 	// This test verifies that we're using kebab-case for git config keys
 	// rather than camelCase, which is more conventional for git config
@@ -408,7 +534,7 @@ func TestGitConfigKebabCaseKeys(t *testing.T) {
 		{
 			name:     "Branch prefix uses kebab-case",
 			gitKey:   "review.branch-prefix",
-			viperKey: "project.branch-prefix",
+			viperKey: "branch-prefix",
 		},
 	}
 
@@ -614,3 +740,97 @@ func joinNonEmpty(items []string, sep string) string {
 	}
 	return result
 }
+
+func TestGitConfigSettings(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", "")
+	defer testRepo.Cleanup()
+
+	included := filepath.Join(t.TempDir(), "work.gitconfig")
+	if err := os.WriteFile(included, []byte("[review]\n\tdraft = yes\n\tteam-reviewers = acme/backend, acme/infra\n"), 0644); err != nil {
+		t.Fatalf("Failed to write included config: %v", err)
+	}
+	testRepo.GitExec("config", "includeIf.gitdir:"+testRepo.Dir+"/.path", included)
+
+	testRepo.GitExec("config", "review.open-browser", "off")
+	testRepo.GitExec("config", "review.merge-method", "squash")
+	testRepo.GitExec("config", "review.project-labels", "legacy")
+	testRepo.GitExec("config", "review.default-reviewers", "legacy")
+	testRepo.GitExec("config", "review.reviewers", "alice, bob")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if !viper.GetBool("draft") {
+			t.Error("Expected draft from the included config to be true")
+		}
+		if viper.GetBool("open-browser") {
+			t.Error("Expected open-browser=off to be false")
+		}
+		if got := viper.GetString("merge-method"); got != "squash" {
+			t.Errorf("Expected merge-method 'squash', got %q", got)
+		}
+
+		lists := map[string][]string{
+			"team-reviewers": {"acme/backend", "acme/infra"},
+			"reviewers":      {"alice", "bob"},
+			"labels":         {"legacy"},
+		}
+		for setting, expected := range lists {
+			if got := viper.GetStringSlice(setting); !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %s to be %v, got %v", setting, expected, got)
+			}
+		}
+
+		sources := map[string]string{
+			"reviewers": "git review.reviewers",
+			"labels":    "git review.project-labels",
+			"draft":     "git review.draft",
+		}
+		for setting, expected := range sources {
+			if got := settingSource(nil, setting); got != expected {
+				t.Errorf("Expected source of %s to be %q, got %q", setting, expected, got)
+			}
+		}
+	})
+}
+
+func TestValidateGitConfig(t *testing.T) {
+	problems := validateGitConfig(map[string]string{
+		"review.draft":           "maybe",
+		"review.open-browser":    "true",
+		"review.project-labels":  "x",
+		"review.user-identifier": "jt",
+		"review.no-such-setting": "x",
+	})
+
+	expected := []string{
+		`invalid boolean review.draft="maybe"`,
+		"unknown git config key review.no-such-setting",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems)
+	}
+}
+
+func TestParseHooks(t *testing.T) {
+	testRepo := newConfigTestRepo(t, `
+post-stack-hook: ./scripts/notify
+`, "")
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.pre-push-hook", "./scripts/policy --strict")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		expected := hooks.Hooks{
+			hooks.PrePush:   "./scripts/policy --strict",
+			hooks.PostStack: "./scripts/notify",
+		}
+		if got := parseHooks(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected hooks %v, got %v", expected, got)
+		}
+	})
+}
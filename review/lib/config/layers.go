@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Layer is a set of settings loaded from one configuration source. Layers
+// are merged in the order they are loaded, later layers taking precedence.
+type Layer struct {
+	Name       string                 // "user", "project", "git", ...
+	Source     string                 // where the values came from, e.g. a file path
+	Values     map[string]interface{} // keyed by setting name
+	KeySources map[string]string      // optional per-setting source, e.g. the git config key
+}
+
+// loadedLayers records the layers applied by InitConfig, lowest precedence first
+var loadedLayers []Layer
+
+// applyLayer merges the layer's values into viper's config and records it so
+// the source of each setting can be reported
+func applyLayer(layer Layer) {
+	if len(layer.Values) == 0 {
+		return
+	}
+	if err := viper.MergeConfigMap(layer.Values); err != nil {
+		slog.Warn("failed to apply config", "layer", layer.Name, "source", layer.Source, "err", err)
+		return
+	}
+	loadedLayers = append(loadedLayers, layer)
+}
+
+// userConfigFiles lists the user-level config file locations, in the order they are searched
+func userConfigFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".config", "git-review.yaml"),
+		filepath.Join(home, ".git-review.yaml"),
+		filepath.Join(home, "git-review.yaml"),
+	}
+}
+
+// userConfigFile returns the path of the user-level config file, or "" if there is none
+func userConfigFile() string {
+	for _, path := range userConfigFiles() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// readConfigFile reads a yaml config file
+func readConfigFile(path string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return v, nil
+}
+
+// userSections are the top-level keys of the user config file that hold
+// sections rather than settings
+var userSections = map[string]bool{
+	"profiles": true,
+	"repos":    true,
+}
+
+// isUserSectionKey reports whether a (dotted) key belongs to a user config section
+func isUserSectionKey(key string) bool {
+	section, _, _ := strings.Cut(key, ".")
+	return userSections[section]
+}
+
+// settingValues returns the settings in a config file, skipping its sections
+func settingValues(v *viper.Viper) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		if !isUserSectionKey(key) {
+			values[key] = normalizeValue(key, v.Get(key))
+		}
+	}
+	return values
+}
+
+// envName returns the environment variable that configures a setting
+func envName(setting string) string {
+	return "REVIEW_" + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+// settingSource describes where the effective value of a setting comes from.
+// cmd may be nil if command-line flags should not be considered.
+func settingSource(cmd *cobra.Command, setting string) string {
+	if cmd != nil {
+		if flag := cmd.Flags().Lookup(setting); flag != nil && flag.Changed {
+			return "flag --" + setting
+		}
+	}
+
+	if value, ok := os.LookupEnv(envName(setting)); ok && value != "" {
+		return "env " + envName(setting)
+	}
+
+	for i := len(loadedLayers) - 1; i >= 0; i-- {
+		layer := loadedLayers[i]
+		if _, ok := layer.Values[setting]; !ok {
+			continue
+		}
+		if source, ok := layer.KeySources[setting]; ok {
+			return layer.Name + " " + source
+		}
+		return layer.Name + " " + layer.Source
+	}
+
+	return "default"
+}
+
+// settingNames returns the name of every setting of the review and stack
+// commands, in definition order
+func settingNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, configs := range [][]FlagConfig{flagConfigs, stackFlagConfigs} {
+		for _, flag := range configs {
+			if !seen[flag.Name] {
+				seen[flag.Name] = true
+				names = append(names, flag.Name)
+			}
+		}
+	}
+	return names
+}
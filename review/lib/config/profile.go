@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jtamagnan/git-utils/git"
+	"github.com/spf13/viper"
+)
+
+// profileKeys are the settings a profile may bundle
+var profileKeys = map[string]bool{
+	"labels":       true,
+	"reviewers":    true,
+	"draft":        true,
+	"auto-merge":   true,
+	"merge-method": true,
+	"template":     true,
+	"parent":       true,
+}
+
+// Profile is a named bundle of settings from the "profiles" section of the
+// user config file
+type Profile struct {
+	Name     string
+	Values   map[string]interface{}
+	Branches []string // branch name globs that activate the profile automatically
+	Remotes  []string // "owner/repo" or remote URL globs that activate the profile automatically
+}
+
+// repoContext describes the current checkout, for matching profiles and
+// repository overrides
+type repoContext struct {
+	Branch    string // current branch name
+	RemoteURL string // URL of the upstream remote
+	FullName  string // "owner/repo" parsed from RemoteURL
+}
+
+// currentRepoContext inspects the repository in the current directory. Fields
+// that cannot be determined are left empty.
+func currentRepoContext() repoContext {
+	var ctx repoContext
+
+	repo, err := git.GetRepository()
+	if err != nil {
+		return ctx
+	}
+
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		ctx.Branch = head.Name().Short()
+	}
+
+	remote, err := repo.Remote()
+	if err != nil {
+		remote = "origin"
+	}
+	if url, err := repo.GetRemoteURL(remote); err == nil {
+		ctx.RemoteURL = url
+		if info, err := git.ParseRepositoryInfo(url); err == nil {
+			ctx.FullName = info.Owner + "/" + info.Name
+		}
+	}
+
+	return ctx
+}
+
+// matchesAny reports whether value is non-empty and matches one of the globs
+func matchesAny(globs []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, glob := range globs {
+		if ok, err := path.Match(glob, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// toStringSlice converts a yaml list or comma-separated string to a []string
+func toStringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return splitAndTrim(v)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return items
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// normalizeValue converts comma-separated strings into lists for list settings
+func normalizeValue(setting string, value interface{}) interface{} {
+	if s, ok := value.(string); ok && settingType(setting) == "commastring" {
+		return ParseCommaString(s).ToStringSlice()
+	}
+	return value
+}
+
+// parseProfiles parses the "profiles" section of the user config file.
+// Unsupported keys are reported as problems and ignored.
+func parseProfiles(section interface{}) (map[string]Profile, []string) {
+	profiles := make(map[string]Profile)
+	var problems []string
+
+	raw, ok := section.(map[string]interface{})
+	if !ok {
+		if section != nil {
+			problems = append(problems, "\"profiles\" must be a map of profile names to settings")
+		}
+		return profiles, problems
+	}
+
+	for name, body := range raw {
+		settings, ok := body.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("profile %q must be a map of settings", name))
+			continue
+		}
+
+		profile := Profile{Name: name, Values: make(map[string]interface{})}
+		for key, value := range settings {
+			switch {
+			case key == "match":
+				match, _ := value.(map[string]interface{})
+				profile.Branches = toStringSlice(match["branches"])
+				profile.Remotes = toStringSlice(match["remotes"])
+			case profileKeys[key]:
+				profile.Values[key] = normalizeValue(key, value)
+			default:
+				problems = append(problems, fmt.Sprintf("profile %q: unsupported key %q", name, key))
+			}
+		}
+		profiles[name] = profile
+	}
+
+	sort.Strings(problems)
+	return profiles, problems
+}
+
+// selectProfile picks the profile to apply: the requested one if set,
+// otherwise the first profile (by name) whose branch or remote patterns
+// match the current checkout. The returned reason describes the selection.
+func selectProfile(profiles map[string]Profile, requested string, ctx repoContext) (*Profile, string, error) {
+	if requested != "" {
+		profile, ok := profiles[strings.ToLower(requested)]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown profile %q", requested)
+		}
+		return &profile, "selected", nil
+	}
+
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := profiles[name]
+		switch {
+		case matchesAny(profile.Branches, ctx.Branch):
+			return &profile, "branch " + ctx.Branch, nil
+		case matchesAny(profile.Remotes, ctx.FullName):
+			return &profile, "remote " + ctx.FullName, nil
+		case matchesAny(profile.Remotes, ctx.RemoteURL):
+			return &profile, "remote " + ctx.RemoteURL, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// profileError is set by InitConfig when the requested profile does not exist
+var profileError error
+
+// applyProfile selects a profile from the user config and applies it as a layer
+func applyProfile(section interface{}, ctx repoContext) {
+	profileError = nil
+
+	profiles, problems := parseProfiles(section)
+	for _, problem := range problems {
+		slog.Warn(problem)
+	}
+
+	profile, reason, err := selectProfile(profiles, viper.GetString("profile"), ctx)
+	if err != nil {
+		profileError = err
+		return
+	}
+	if profile == nil {
+		return
+	}
+
+	applyLayer(Layer{
+		Name:   "profile",
+		Source: fmt.Sprintf("%s (%s)", profile.Name, reason),
+		Values: profile.Values,
+	})
+}
+
+// checkProfile returns an error if a profile was requested that does not exist
+func checkProfile() error {
+	if profileError != nil {
+		return fmt.Errorf("%v: define it under \"profiles\" in your user config file", profileError)
+	}
+	return nil
+}
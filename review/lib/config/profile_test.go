@@ -0,0 +1,194 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const profilesUserConfig = `
+labels: [user-label]
+profiles:
+  hotfix:
+    labels: [hotfix, urgent]
+    reviewers: oncall
+    auto-merge: true
+    merge-method: squash
+    parent: release
+    match:
+      branches: ["hotfix/*"]
+  oss:
+    draft: true
+    match:
+      remotes: ["torvalds/*"]
+`
+
+func TestParseProfiles(t *testing.T) {
+	section := map[string]interface{}{
+		"hotfix": map[string]interface{}{
+			"labels":       "hotfix, urgent",
+			"open-browser": false,
+			"match": map[string]interface{}{
+				"branches": []interface{}{"hotfix/*"},
+				"remotes":  "acme/*",
+			},
+		},
+		"broken": "not a map",
+	}
+
+	profiles, problems := parseProfiles(section)
+
+	hotfix, ok := profiles["hotfix"]
+	if !ok {
+		t.Fatal("Expected hotfix profile to be parsed")
+	}
+	if !reflect.DeepEqual(hotfix.Values, map[string]interface{}{"labels": []string{"hotfix", "urgent"}}) {
+		t.Errorf("Unexpected profile values: %#v", hotfix.Values)
+	}
+	if !reflect.DeepEqual(hotfix.Branches, []string{"hotfix/*"}) || !reflect.DeepEqual(hotfix.Remotes, []string{"acme/*"}) {
+		t.Errorf("Unexpected match patterns: branches=%v remotes=%v", hotfix.Branches, hotfix.Remotes)
+	}
+
+	expectedProblems := []string{
+		`profile "broken" must be a map of settings`,
+		`profile "hotfix": unsupported key "open-browser"`,
+	}
+	if !reflect.DeepEqual(problems, expectedProblems) {
+		t.Errorf("Expected problems %v, got %v", expectedProblems, problems)
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	profiles := map[string]Profile{
+		"hotfix":  {Name: "hotfix", Branches: []string{"hotfix/*"}},
+		"oss":     {Name: "oss", Remotes: []string{"torvalds/*"}},
+		"release": {Name: "release", Remotes: []string{"git@github.com:acme/*"}},
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		ctx       repoContext
+		expected  string
+		reason    string
+	}{
+		{"explicit selection wins", "OSS", repoContext{Branch: "hotfix/x"}, "oss", "selected"},
+		{"branch pattern", "", repoContext{Branch: "hotfix/login"}, "hotfix", "branch hotfix/login"},
+		{"owner/repo pattern", "", repoContext{Branch: "main", FullName: "torvalds/linux"}, "oss", "remote torvalds/linux"},
+		{"remote URL pattern", "", repoContext{RemoteURL: "git@github.com:acme/app.git"}, "release", "remote git@github.com:acme/app.git"},
+		{"no match", "", repoContext{Branch: "main", FullName: "acme/app"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, reason, err := selectProfile(profiles, tt.requested, tt.ctx)
+			if err != nil {
+				t.Fatalf("selectProfile failed: %v", err)
+			}
+			name := ""
+			if profile != nil {
+				name = profile.Name
+			}
+			if name != tt.expected || reason != tt.reason {
+				t.Errorf("selectProfile() = (%q, %q), expected (%q, %q)", name, reason, tt.expected, tt.reason)
+			}
+		})
+	}
+
+	if _, _, err := selectProfile(profiles, "missing", repoContext{}); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestProfileSelectedFromEnvironment(t *testing.T) {
+	testRepo := newConfigTestRepo(t, profilesUserConfig, "")
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.merge-method", "rebase")
+	t.Setenv("REVIEW_PROFILE", "hotfix")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		cmd := &cobra.Command{Use: "test"}
+		SetupFlags(cmd)
+		parsedArgs, err := ParseArgs(cmd, []string{})
+		if err != nil {
+			t.Fatalf("ParseArgs failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(parsedArgs.Labels, []string{"hotfix", "urgent"}) {
+			t.Errorf("Expected profile labels, got %v", parsedArgs.Labels)
+		}
+		if !reflect.DeepEqual(parsedArgs.Reviewers, []string{"oncall"}) {
+			t.Errorf("Expected profile reviewers, got %v", parsedArgs.Reviewers)
+		}
+		if !parsedArgs.AutoMerge || parsedArgs.Parent != "release" {
+			t.Errorf("Expected auto-merge and parent from profile, got auto-merge=%v parent=%q", parsedArgs.AutoMerge, parsedArgs.Parent)
+		}
+		// Profiles override git config
+		if parsedArgs.MergeMethod != "squash" {
+			t.Errorf("Expected profile merge-method to override git config, got %q", parsedArgs.MergeMethod)
+		}
+		if source := settingSource(cmd, "labels"); source != "profile hotfix (selected)" {
+			t.Errorf("Expected labels to come from the profile, got %q", source)
+		}
+	})
+}
+
+func TestProfileActivatedByBranch(t *testing.T) {
+	testRepo := newConfigTestRepo(t, profilesUserConfig, "")
+	defer testRepo.Cleanup()
+
+	testRepo.AddCommit("file.txt", "content", "Initial commit")
+	testRepo.GitExec("checkout", "-b", "hotfix/login")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		cmd := &cobra.Command{Use: "test"}
+		SetupFlags(cmd)
+		if err := cmd.ParseFlags([]string{"--labels", "from-flag"}); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		parsedArgs, err := ParseArgs(cmd, []string{})
+		if err != nil {
+			t.Fatalf("ParseArgs failed: %v", err)
+		}
+
+		// Flags still override the profile
+		if !reflect.DeepEqual(parsedArgs.Labels, []string{"from-flag"}) {
+			t.Errorf("Expected flag labels to override the profile, got %v", parsedArgs.Labels)
+		}
+		if parsedArgs.Parent != "release" {
+			t.Errorf("Expected parent from the branch-matched profile, got %q", parsedArgs.Parent)
+		}
+		if source := settingSource(cmd, "parent"); source != "profile hotfix (branch hotfix/login)" {
+			t.Errorf("Unexpected source for parent: %q", source)
+		}
+	})
+}
+
+func TestUnknownProfileIsAnError(t *testing.T) {
+	testRepo := newConfigTestRepo(t, profilesUserConfig, "")
+	defer testRepo.Cleanup()
+
+	t.Setenv("REVIEW_PROFILE", "missing")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		cmd := &cobra.Command{Use: "stack"}
+		SetupStackFlags(cmd)
+		_, err := ParseStackArgs(cmd, []string{})
+		if err == nil || !strings.Contains(err.Error(), `unknown profile "missing"`) {
+			t.Errorf("Expected unknown profile error, got: %v", err)
+		}
+	})
+}
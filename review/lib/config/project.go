@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProjectConfigFile is the checked-in, repository-level config file
+const ProjectConfigFile = ".git-review.yaml"
+
+// projectKeys maps the keys allowed in ProjectConfigFile to the settings they
+// configure. Only settings that describe the project belong here; personal
+// workflow settings such as open-browser, draft or no-verify stay user-level.
+var projectKeys = map[string]string{
+	"reviewers":      "reviewers",
+	"team-reviewers": "team-reviewers",
+	"labels":         "labels",
+	"branch-name":    "branch-name",
+	"branch-prefix":  "branch-prefix",
+	"template":       "template",
+	"merge-method":   "merge-method",
+	"lint.checks":    "lint-checks",
+}
+
+// lintOnlyKeys are keys of ProjectConfigFile that are read by git-lint rather
+// than git-review, they are accepted without a warning
+var lintOnlyKeys = map[string]bool{
+	"lint.runner":        true,
+	"lint.command":       true,
+	"lint.make-target":   true,
+	"lint.timeout":       true,
+	"lint.check-timeout": true,
+}
+
+// lintOnlyPrefixes start keys of ProjectConfigFile nested under git-lint
+// settings, e.g. "lint.builtin.skip" or "lint.env.GOFLAGS"
+var lintOnlyPrefixes = []string{"lint.builtin.", "lint.env."}
+
+// isLintOnlyKey reports whether key is read by git-lint rather than git-review
+func isLintOnlyKey(key string) bool {
+	if lintOnlyKeys[key] {
+		return true
+	}
+	for _, prefix := range lintOnlyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadProjectConfig reads ProjectConfigFile from the repository root and
+// returns the allowed settings keyed by setting name. Keys outside the
+// allowlist are ignored with a warning. A missing file is not an error.
+func LoadProjectConfig(root string) (map[string]interface{}, error) {
+	values, rejected, err := loadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range rejected {
+		slog.Warn(rejectedProjectKeyMessage(key))
+	}
+
+	return values, nil
+}
+
+// loadProjectConfig reads ProjectConfigFile from the repository root and
+// returns the allowed settings and the sorted list of rejected keys
+func loadProjectConfig(root string) (map[string]interface{}, []string, error) {
+	path := filepath.Join(root, ProjectConfigFile)
+	if _, err := os.Stat(path); err != nil {
+		return map[string]interface{}{}, nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", ProjectConfigFile, err)
+	}
+
+	values := make(map[string]interface{})
+	var rejected []string
+	for _, key := range v.AllKeys() {
+		if isLintOnlyKey(key) {
+			continue
+		}
+		setting, ok := projectKeys[key]
+		if !ok {
+			rejected = append(rejected, key)
+			continue
+		}
+		value := normalizeValue(setting, v.Get(key))
+		if path, ok := value.(string); ok && setting == "template" && !isRepoPath(root, path) {
+			rejected = append(rejected, key)
+			continue
+		}
+		values[setting] = value
+	}
+	sort.Strings(rejected)
+
+	return values, rejected, nil
+}
+
+// isRepoPath reports whether path, relative to root, names a file inside
+// root. Symlinks are followed so a checked-in link cannot point outside it.
+func isRepoPath(root, path string) bool {
+	if !filepath.IsLocal(path) {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, path))
+	if err != nil {
+		// A missing template is reported when it is read
+		return true
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realRoot, resolved)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// rejectedProjectKeyMessage explains why a key in ProjectConfigFile is ignored
+func rejectedProjectKeyMessage(key string) string {
+	if key == "template" {
+		return fmt.Sprintf("ignoring %q in %s: it must be a relative path inside the repository", key, ProjectConfigFile)
+	}
+	if isKnownSetting(key) {
+		return fmt.Sprintf("ignoring %q in %s: it is a personal preference and can only be set in your user config, git config or environment", key, ProjectConfigFile)
+	}
+	return fmt.Sprintf("ignoring unknown key %q in %s", key, ProjectConfigFile)
+}
+
+// projectKey returns the ProjectConfigFile key for a setting, if the setting
+// may be set per project
+func projectKey(setting string) (string, bool) {
+	for key, name := range projectKeys {
+		if name == setting {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// settingType returns the flag type of a setting, or "" if it is not a flag
+func settingType(name string) string {
+	for _, configs := range [][]FlagConfig{flagConfigs, stackFlagConfigs, commentsFlagConfigs} {
+		for _, flag := range configs {
+			if flag.Name == name {
+				return flag.Type
+			}
+		}
+	}
+	return ""
+}
+
+// isKnownSetting reports whether name is a setting of any review command
+func isKnownSetting(name string) bool {
+	return settingType(name) != ""
+}
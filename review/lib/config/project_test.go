@@ -0,0 +1,306 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jtamagnan/git-utils/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newConfigTestRepo creates a repository with the given .git-review.yaml and a
+// user config file in a temporary HOME. Empty contents skip the file.
+func newConfigTestRepo(t *testing.T, userConfig, projectConfig string) *git.TestRepo {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if userConfig != "" {
+		if err := os.MkdirAll(filepath.Join(home, ".config"), 0755); err != nil {
+			t.Fatalf("Failed to create config dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(home, ".config", "git-review.yaml"), []byte(userConfig), 0644); err != nil {
+			t.Fatalf("Failed to write user config: %v", err)
+		}
+	}
+
+	testRepo := git.NewTestRepo(t)
+	if projectConfig != "" {
+		testRepo.CreateFile(ProjectConfigFile, projectConfig)
+	}
+	return testRepo
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+reviewers: [alice, bob]
+team-reviewers: acme/backend, acme/infra
+labels:
+  - backend
+branch-prefix: feature
+template: .github/review_template.md
+merge-method: squash
+lint:
+  checks: gofmt,govet
+`)
+	defer testRepo.Cleanup()
+
+	values, err := LoadProjectConfig(testRepo.Dir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"reviewers":      []interface{}{"alice", "bob"},
+		"team-reviewers": []string{"acme/backend", "acme/infra"},
+		"labels":         []interface{}{"backend"},
+		"branch-prefix":  "feature",
+		"template":       ".github/review_template.md",
+		"merge-method":   "squash",
+		"lint-checks":    []string{"gofmt", "govet"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("LoadProjectConfig() = %#v, expected %#v", values, expected)
+	}
+}
+
+func TestLoadProjectConfigMissingAndInvalid(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", "")
+	defer testRepo.Cleanup()
+
+	values, err := LoadProjectConfig(testRepo.Dir)
+	if err != nil || len(values) != 0 {
+		t.Errorf("Expected no settings and no error without a project config, got %v, %v", values, err)
+	}
+
+	testRepo.CreateFile(ProjectConfigFile, "labels: [unterminated")
+	if _, err := LoadProjectConfig(testRepo.Dir); err == nil {
+		t.Error("Expected error for invalid YAML")
+	}
+}
+
+func TestProjectConfigRejectsBehavioralKeys(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+open-browser: false
+draft: true
+no-verify: true
+unknown-setting: value
+lint:
+  unknown: value
+labels: [backend]
+`)
+	defer testRepo.Cleanup()
+
+	values, err := LoadProjectConfig(testRepo.Dir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig failed: %v", err)
+	}
+	if len(values) != 1 || values["labels"] == nil {
+		t.Errorf("Expected only labels to be loaded, got %v", values)
+	}
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if !viper.GetBool("open-browser") || viper.GetBool("draft") || viper.GetBool("no-verify") {
+			t.Error("Expected behavioral settings in the project config to be ignored")
+		}
+	})
+}
+
+func TestProjectConfigAcceptsLintRunnerKeys(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+lint:
+  checks: gofmt
+  runner: make
+  command: ./scripts/lint.sh
+  make-target: lint
+  timeout: 10m
+  check-timeout: 2m
+  env:
+    GOFLAGS: -mod=mod
+  builtin:
+    skip: [debug-statements]
+    max-file-size-kb: 1024
+`)
+	defer testRepo.Cleanup()
+
+	values, rejected, err := loadProjectConfig(testRepo.Dir)
+	if err != nil {
+		t.Fatalf("loadProjectConfig failed: %v", err)
+	}
+	if len(rejected) != 0 {
+		t.Errorf("Expected the git-lint keys to be accepted, got rejected %v", rejected)
+	}
+	if !reflect.DeepEqual(values, map[string]interface{}{"lint-checks": []string{"gofmt"}}) {
+		t.Errorf("Expected only lint-checks to be loaded, got %v", values)
+	}
+}
+
+func TestProjectConfigTemplateOutsideRepository(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(outside, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		rejected bool
+	}{
+		{"Relative", "review_template.md", false},
+		{"Missing", "docs/missing.md", false},
+		{"Absolute", outside, true},
+		{"ParentDirectory", "../../.ssh/id_ed25519", true},
+		{"SymlinkOutside", "link.md", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testRepo := newConfigTestRepo(t, "", "template: "+test.template+"\n")
+			defer testRepo.Cleanup()
+			testRepo.CreateFile("review_template.md", "template")
+			if err := os.Symlink(outside, filepath.Join(testRepo.Dir, "link.md")); err != nil {
+				t.Skipf("symlinks not supported: %v", err)
+			}
+
+			values, rejected, err := loadProjectConfig(testRepo.Dir)
+			if err != nil {
+				t.Fatalf("loadProjectConfig failed: %v", err)
+			}
+			if test.rejected {
+				if _, ok := values["template"]; ok || !reflect.DeepEqual(rejected, []string{"template"}) {
+					t.Errorf("Expected template %q to be rejected, got values %v, rejected %v", test.template, values, rejected)
+				}
+			} else if values["template"] != test.template || len(rejected) != 0 {
+				t.Errorf("Expected template %q to be loaded, got values %v, rejected %v", test.template, values, rejected)
+			}
+		})
+	}
+}
+
+func TestProjectConfigOverridesUserConfig(t *testing.T) {
+	testRepo := newConfigTestRepo(t, `
+open-browser: false
+labels: [user-label]
+reviewers: [user-reviewer]
+`, `
+labels: [project-label]
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if labels := viper.GetStringSlice("labels"); !reflect.DeepEqual(labels, []string{"project-label"}) {
+			t.Errorf("Expected project labels to override user labels, got %v", labels)
+		}
+		// Settings the project does not set keep their user values
+		if reviewers := viper.GetStringSlice("reviewers"); !reflect.DeepEqual(reviewers, []string{"user-reviewer"}) {
+			t.Errorf("Expected user reviewers, got %v", reviewers)
+		}
+		if viper.GetBool("open-browser") {
+			t.Error("Expected user open-browser setting to be kept")
+		}
+	})
+}
+
+func TestGitConfigOverridesProjectConfig(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+reviewers: [alice]
+merge-method: squash
+branch-prefix: feature
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.default-reviewers", "bob")
+	testRepo.GitExec("config", "review.merge-method", "rebase")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if reviewers := viper.GetStringSlice("reviewers"); !reflect.DeepEqual(reviewers, []string{"bob"}) {
+			t.Errorf("Expected git config reviewers to override project reviewers, got %v", reviewers)
+		}
+		if mergeMethod := viper.GetString("merge-method"); mergeMethod != "rebase" {
+			t.Errorf("Expected git config merge-method 'rebase', got %q", mergeMethod)
+		}
+		if branchPrefix := viper.GetString("branch-prefix"); branchPrefix != "feature" {
+			t.Errorf("Expected project branch-prefix 'feature', got %q", branchPrefix)
+		}
+	})
+}
+
+func TestEnvironmentOverridesProjectConfig(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+branch-prefix: feature
+`)
+	defer testRepo.Cleanup()
+
+	t.Setenv("REVIEW_BRANCH_PREFIX", "from-env")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if branchPrefix := viper.GetString("branch-prefix"); branchPrefix != "from-env" {
+			t.Errorf("Expected environment to override project branch-prefix, got %q", branchPrefix)
+		}
+	})
+}
+
+func TestFlagsOverrideProjectAndGitConfig(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+labels: [project-label]
+merge-method: squash
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.merge-method", "rebase")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		cmd := &cobra.Command{Use: "test"}
+		SetupFlags(cmd)
+		if err := cmd.ParseFlags([]string{"--labels", "flag-label", "--merge-method", "merge"}); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+
+		parsedArgs, err := ParseArgs(cmd, []string{})
+		if err != nil {
+			t.Fatalf("ParseArgs failed: %v", err)
+		}
+		if !reflect.DeepEqual(parsedArgs.Labels, []string{"flag-label"}) {
+			t.Errorf("Expected flag labels to override project labels, got %v", parsedArgs.Labels)
+		}
+		if parsedArgs.MergeMethod != "merge" {
+			t.Errorf("Expected flag merge-method to override git config, got %q", parsedArgs.MergeMethod)
+		}
+	})
+}
+
+func TestInvalidMergeMethod(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+merge-method: fast-forward
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		cmd := &cobra.Command{Use: "test"}
+		SetupFlags(cmd)
+		if _, err := ParseArgs(cmd, []string{}); err == nil {
+			t.Error("Expected error for invalid merge-method")
+		}
+	})
+}
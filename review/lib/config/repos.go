@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// repoOverride is one entry of the "repos" section of the user config file:
+// settings applied to repositories whose "owner/repo" matches Pattern
+type repoOverride struct {
+	Pattern string
+	Values  map[string]interface{}
+}
+
+// parseRepoOverrides parses the "repos" section of the user config file.
+// Unknown settings are reported as problems and ignored.
+func parseRepoOverrides(section interface{}) ([]repoOverride, []string) {
+	var overrides []repoOverride
+	var problems []string
+
+	raw, ok := section.(map[string]interface{})
+	if !ok {
+		if section != nil {
+			problems = append(problems, "\"repos\" must be a map of owner/repo patterns to settings")
+		}
+		return overrides, problems
+	}
+
+	for pattern, body := range raw {
+		settings, ok := body.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("repos %q must be a map of settings", pattern))
+			continue
+		}
+
+		override := repoOverride{Pattern: pattern, Values: make(map[string]interface{})}
+		for key, value := range settings {
+			if !isKnownSetting(key) {
+				problems = append(problems, fmt.Sprintf("repos %q: unknown key %q", pattern, key))
+				continue
+			}
+			override.Values[key] = normalizeValue(key, value)
+		}
+		overrides = append(overrides, override)
+	}
+
+	sortBySpecificity(overrides)
+	sort.Strings(problems)
+	return overrides, problems
+}
+
+// sortBySpecificity orders overrides so that more specific patterns come
+// last and therefore win: patterns with fewer wildcards are more specific,
+// and ties are broken by name for a stable order
+func sortBySpecificity(overrides []repoOverride) {
+	wildcards := func(pattern string) int {
+		return strings.Count(pattern, "*") + strings.Count(pattern, "?") + strings.Count(pattern, "[")
+	}
+	sort.SliceStable(overrides, func(i, j int) bool {
+		wi, wj := wildcards(overrides[i].Pattern), wildcards(overrides[j].Pattern)
+		if wi != wj {
+			return wi > wj
+		}
+		return overrides[i].Pattern < overrides[j].Pattern
+	})
+}
+
+// applyRepoOverrides applies every entry of the "repos" section matching
+// fullName ("owner/repo") as its own layer, least specific first
+func applyRepoOverrides(section interface{}, fullName, source string) {
+	overrides, problems := parseRepoOverrides(section)
+	for _, problem := range problems {
+		slog.Warn(problem)
+	}
+
+	// Keys in the config file are case-insensitive, so match in lower case
+	fullName = strings.ToLower(fullName)
+	for _, override := range overrides {
+		if matchesAny([]string{override.Pattern}, fullName) {
+			applyLayer(Layer{
+				Name:   "repos",
+				Source: fmt.Sprintf("%s (%s)", override.Pattern, source),
+				Values: override.Values,
+			})
+		}
+	}
+}
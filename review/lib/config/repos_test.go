@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const reposUserConfig = `
+labels: [personal]
+reviewers: [friend]
+repos:
+  acme/*:
+    labels: [work]
+    reviewers: work-team
+  acme/payments:
+    reviewers: [payments-team]
+  acme/site.github.io:
+    labels: [docs]
+  torvalds/*:
+    open-browser: false
+    colour: blue
+`
+
+func TestParseRepoOverrides(t *testing.T) {
+	v, err := readConfigFileFromString(t, reposUserConfig)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	overrides, problems := parseRepoOverrides(v.Get("repos"))
+
+	var patterns []string
+	for _, override := range overrides {
+		patterns = append(patterns, override.Pattern)
+	}
+	// Wildcard patterns first so that exact matches win
+	expected := []string{"acme/*", "torvalds/*", "acme/payments", "acme/site.github.io"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Expected patterns in order %v, got %v", expected, patterns)
+	}
+
+	if !reflect.DeepEqual(overrides[0].Values["reviewers"], []string{"work-team"}) {
+		t.Errorf("Expected comma string to be parsed as a list, got %#v", overrides[0].Values["reviewers"])
+	}
+
+	if len(problems) != 1 || !strings.Contains(problems[0], `unknown key "colour"`) {
+		t.Errorf("Expected one problem for the unknown key, got %v", problems)
+	}
+}
+
+// readConfigFileFromString writes content to a temporary file and reads it
+func readConfigFileFromString(t *testing.T, content string) (*viper.Viper, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "git-review.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return readConfigFile(path)
+}
+
+func TestRepoOverridesMatchUpstream(t *testing.T) {
+	tests := []struct {
+		name              string
+		remoteURL         string
+		expectedLabels    []string
+		expectedReviewers []string
+		reviewersSource   string
+	}{
+		{
+			name:              "org-wide pattern",
+			remoteURL:         "git@github.com:acme/app.git",
+			expectedLabels:    []string{"work"},
+			expectedReviewers: []string{"work-team"},
+			reviewersSource:   "repos acme/*",
+		},
+		{
+			name:              "exact repository wins over the org pattern",
+			remoteURL:         "https://github.com/Acme/Payments.git",
+			expectedLabels:    []string{"work"},
+			expectedReviewers: []string{"payments-team"},
+			reviewersSource:   "repos acme/payments",
+		},
+		{
+			name:              "repository names with dots",
+			remoteURL:         "https://github.com/acme/site.github.io.git",
+			expectedLabels:    []string{"docs"},
+			expectedReviewers: []string{"work-team"},
+			reviewersSource:   "repos acme/*",
+		},
+		{
+			name:              "no matching pattern",
+			remoteURL:         "https://github.com/someone/else.git",
+			expectedLabels:    []string{"personal"},
+			expectedReviewers: []string{"friend"},
+			reviewersSource:   "user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRepo := newConfigTestRepo(t, reposUserConfig, "")
+			defer testRepo.Cleanup()
+			testRepo.AddRemote("origin", tt.remoteURL)
+
+			testRepo.InDir(func() {
+				viper.Reset()
+				InitConfig()
+
+				if labels := viper.GetStringSlice("labels"); !reflect.DeepEqual(labels, tt.expectedLabels) {
+					t.Errorf("Expected labels %v, got %v", tt.expectedLabels, labels)
+				}
+				if reviewers := viper.GetStringSlice("reviewers"); !reflect.DeepEqual(reviewers, tt.expectedReviewers) {
+					t.Errorf("Expected reviewers %v, got %v", tt.expectedReviewers, reviewers)
+				}
+				if source := settingSource(nil, "reviewers"); !strings.HasPrefix(source, tt.reviewersSource) {
+					t.Errorf("Expected reviewers source to start with %q, got %q", tt.reviewersSource, source)
+				}
+			})
+		})
+	}
+}
+
+func TestProjectConfigOverridesRepoOverrides(t *testing.T) {
+	testRepo := newConfigTestRepo(t, reposUserConfig, `
+labels: [project-label]
+`)
+	defer testRepo.Cleanup()
+	testRepo.AddRemote("origin", "https://github.com/acme/app.git")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		if labels := viper.GetStringSlice("labels"); !reflect.DeepEqual(labels, []string{"project-label"}) {
+			t.Errorf("Expected project labels to override repo overrides, got %v", labels)
+		}
+		if problems := strings.Join(Validate(), "\n"); !strings.Contains(problems, `repos "torvalds/*": unknown key "colour"`) {
+			t.Errorf("Expected validate to report the unknown key in repos, got:\n%s", problems)
+		}
+	})
+}
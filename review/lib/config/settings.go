@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jtamagnan/git-utils/git"
+	"github.com/jtamagnan/git-utils/review/lib/ticket"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// WritableLayers are the layers `git review config set` can write to
+var WritableLayers = []string{"git", "git-global", "user", "project"}
+
+// formatValue renders a setting value for display
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// List prints every setting with its effective value and the layer it comes from
+func List(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, name := range settingNames() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", name, formatValue(viper.Get(name)), settingSource(nil, name))
+	}
+	return tw.Flush()
+}
+
+// Get returns the effective value of a setting
+func Get(setting string) (string, error) {
+	if !isKnownSetting(setting) {
+		return "", fmt.Errorf("unknown setting %q", setting)
+	}
+	return formatValue(viper.Get(setting)), nil
+}
+
+// parseSettingValue converts a command-line value to the type of the setting
+func parseSettingValue(setting, value string) (interface{}, error) {
+	switch settingType(setting) {
+	case "commastring":
+		return splitAndTrim(value), nil
+	case "bool":
+		b, ok := parseGitBool(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid value %q for %s: expected a boolean", value, setting)
+		}
+		return b, nil
+	case "":
+		return nil, fmt.Errorf("unknown setting %q", setting)
+	}
+	return value, nil
+}
+
+// Set writes a setting to one of the WritableLayers
+func Set(layer, setting, value string) error {
+	typed, err := parseSettingValue(setting, value)
+	if err != nil {
+		return err
+	}
+
+	switch layer {
+	case "git", "git-global":
+		key := gitConfigKey(setting)
+		args := []string{"config"}
+		if layer == "git-global" {
+			args = append(args, "--global")
+		}
+		args = append(args, key, value)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to set %s: %v\n%s", key, err, out)
+		}
+		return nil
+
+	case "user":
+		path := userConfigFile()
+		if path == "" {
+			files := userConfigFiles()
+			if len(files) == 0 {
+				return fmt.Errorf("cannot locate the home directory for the user config file")
+			}
+			path = files[0]
+		}
+		return writeConfigFile(path, setting, typed)
+
+	case "project":
+		key, ok := projectKey(setting)
+		if !ok {
+			return fmt.Errorf("%s cannot be set in %s: it is not a project setting", setting, ProjectConfigFile)
+		}
+		root, err := repositoryRoot()
+		if err != nil {
+			return err
+		}
+		return writeConfigFile(filepath.Join(root, ProjectConfigFile), key, typed)
+	}
+
+	return fmt.Errorf("unknown layer %q: expected one of %s", layer, strings.Join(WritableLayers, ", "))
+}
+
+// writeConfigFile sets key in a yaml config file, creating the file if
+// needed. Only that key is changed: the case of other keys, their order and
+// the comments are kept.
+func writeConfigFile(path, key string, value interface{}) error {
+	var doc yaml.Node
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if root := doc.Content[0]; root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update %s: the file is not a mapping of settings", path)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %v", key, err)
+	}
+	setYAMLKey(doc.Content[0], strings.Split(key, "."), &valueNode)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// setYAMLKey sets the value at the dotted key path in a mapping node,
+// creating the mappings on the way. Keys match case-insensitively, like viper
+// reads them, and a replaced value keeps its comments and flow style.
+func setYAMLKey(mapping *yaml.Node, path []string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !strings.EqualFold(mapping.Content[i].Value, path[0]) {
+			continue
+		}
+		old := mapping.Content[i+1]
+		if len(path) > 1 {
+			if old.Kind != yaml.MappingNode {
+				old = &yaml.Node{Kind: yaml.MappingNode}
+				mapping.Content[i+1] = old
+			}
+			setYAMLKey(old, path[1:], value)
+			return
+		}
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		if old.Style&yaml.FlowStyle != 0 {
+			value.Style |= yaml.FlowStyle
+		}
+		mapping.Content[i+1] = value
+		return
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) > 1 {
+		child := &yaml.Node{Kind: yaml.MappingNode}
+		setYAMLKey(child, path[1:], value)
+		value = child
+	}
+	mapping.Content = append(mapping.Content, keyNode, value)
+}
+
+// validateGitConfig reports unknown review.* keys and invalid booleans
+func validateGitConfig(entries map[string]string) []string {
+	known := make(map[string]string)
+	for _, setting := range settingNames() {
+		known[gitConfigKey(setting)] = setting
+	}
+	for setting, alias := range gitConfigAliases {
+		known[alias] = setting
+	}
+
+	var keys []string
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		setting, ok := known[key]
+		switch {
+		case gitConfigOnlyKeys[key]:
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown git config key %s", key))
+		case settingType(setting) == "bool":
+			if _, ok := parseGitBool(entries[key]); !ok {
+				problems = append(problems, fmt.Sprintf("invalid boolean %s=%q", key, entries[key]))
+			}
+		}
+	}
+	return problems
+}
+
+// Validate checks the config files, git config and effective settings and
+// returns a description of each problem found
+func Validate() []string {
+	var problems []string
+
+	if path := userConfigFile(); path != "" {
+		userConfig, err := readConfigFile(path)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			keys := userConfig.AllKeys()
+			sort.Strings(keys)
+			for _, key := range keys {
+				if !isKnownSetting(key) && !isUserSectionKey(key) {
+					problems = append(problems, fmt.Sprintf("unknown key %q in %s", key, path))
+				}
+			}
+			_, profileProblems := parseProfiles(userConfig.Get("profiles"))
+			problems = append(problems, profileProblems...)
+			_, repoProblems := parseRepoOverrides(userConfig.Get("repos"))
+			problems = append(problems, repoProblems...)
+		}
+	}
+
+	if root, err := repositoryRoot(); err == nil {
+		_, rejected, err := loadProjectConfig(root)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		for _, key := range rejected {
+			problems = append(problems, rejectedProjectKeyMessage(key))
+		}
+	}
+
+	if entries, err := git.GetConfigRegexp(`^review\.`); err == nil {
+		problems = append(problems, validateGitConfig(entries)...)
+	}
+
+	if err := checkProfile(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := validateMergeMethod(viper.GetString("merge-method")); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := ticket.NewConfig(viper.GetStringSlice("ticket-trackers"), false, viper.GetString("ticket-required")); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if templatePath := viper.GetString("template"); templatePath != "" {
+		if root, err := repositoryRoot(); err == nil && !filepath.IsAbs(templatePath) {
+			templatePath = filepath.Join(root, templatePath)
+		}
+		if _, err := os.Stat(templatePath); err != nil {
+			problems = append(problems, fmt.Sprintf("template %s does not exist", viper.GetString("template")))
+		}
+	}
+
+	return problems
+}
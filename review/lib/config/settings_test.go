@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// listedSources returns the SOURCE column of List output keyed by setting
+func listedSources(t *testing.T) map[string]string {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := List(&out); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	column := strings.Index(lines[0], "SOURCE")
+	sources := make(map[string]string)
+	for _, line := range lines[1:] {
+		sources[strings.Fields(line)[0]] = strings.TrimSpace(line[column:])
+	}
+	return sources
+}
+
+func TestListReportsSources(t *testing.T) {
+	testRepo := newConfigTestRepo(t, `
+labels: [user-label]
+draft: true
+`, `
+labels: [project-label]
+merge-method: squash
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.default-reviewers", "bob")
+	t.Setenv("REVIEW_BRANCH_PREFIX", "env-prefix")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		sources := listedSources(t)
+
+		expected := map[string]string{
+			"draft":         "user " + filepath.Join(os.Getenv("HOME"), ".config", "git-review.yaml"),
+			"labels":        "project " + filepath.Join(testRepo.Dir, ProjectConfigFile),
+			"merge-method":  "project " + filepath.Join(testRepo.Dir, ProjectConfigFile),
+			"reviewers":     "git review.default-reviewers",
+			"branch-prefix": "env REVIEW_BRANCH_PREFIX",
+			"open-browser":  "default",
+		}
+		for setting, source := range expected {
+			if sources[setting] != source {
+				t.Errorf("Expected source of %s to be %q, got %q", setting, source, sources[setting])
+			}
+		}
+
+		for _, name := range settingNames() {
+			if _, ok := sources[name]; !ok {
+				t.Errorf("Expected %s to be listed", name)
+			}
+		}
+	})
+}
+
+func TestGet(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", `
+reviewers: [alice, bob]
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		value, err := Get("reviewers")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if value != "alice,bob" {
+			t.Errorf("Expected 'alice,bob', got %q", value)
+		}
+
+		if _, err := Get("no-such-setting"); err == nil {
+			t.Error("Expected error for unknown setting")
+		}
+	})
+}
+
+func TestSetWritesToLayer(t *testing.T) {
+	testRepo := newConfigTestRepo(t, "", "")
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		if err := Set("git", "reviewers", "alice,bob"); err != nil {
+			t.Fatalf("Set git failed: %v", err)
+		}
+		if value := testRepo.GitExec("config", "review.reviewers"); value != "alice,bob" {
+			t.Errorf("Expected review.reviewers to be 'alice,bob', got %q", value)
+		}
+
+		if err := Set("user", "open-browser", "no"); err != nil {
+			t.Fatalf("Set user failed: %v", err)
+		}
+		if err := Set("project", "lint-checks", "gofmt,govet"); err != nil {
+			t.Fatalf("Set project failed: %v", err)
+		}
+
+		viper.Reset()
+		InitConfig()
+
+		if viper.GetBool("open-browser") {
+			t.Error("Expected open-browser to be false after writing the user config")
+		}
+		if source := settingSource(nil, "open-browser"); !strings.HasPrefix(source, "user ") {
+			t.Errorf("Expected open-browser to come from the user config, got %q", source)
+		}
+		if checks := viper.GetStringSlice("lint-checks"); strings.Join(checks, ",") != "gofmt,govet" {
+			t.Errorf("Expected lint-checks from the project config, got %v", checks)
+		}
+
+		content, err := os.ReadFile(filepath.Join(testRepo.Dir, ProjectConfigFile))
+		if err != nil {
+			t.Fatalf("Failed to read project config: %v", err)
+		}
+		if !strings.Contains(string(content), "lint:") {
+			t.Errorf("Expected lint-checks to be written to the lint section, got:\n%s", content)
+		}
+
+		if err := Set("project", "open-browser", "false"); err == nil {
+			t.Error("Expected error writing a personal setting to the project config")
+		}
+		if err := Set("git", "draft", "maybe"); err == nil {
+			t.Error("Expected error for an invalid boolean")
+		}
+		if err := Set("nowhere", "labels", "x"); err == nil {
+			t.Error("Expected error for an unknown layer")
+		}
+	})
+}
+
+func TestSetKeepsTheRestOfTheFile(t *testing.T) {
+	testRepo := newConfigTestRepo(t, `# shared settings
+Reviewers: [alice]
+labels: [a] # default labels
+lint:
+  # environment of the hooks
+  env:
+    GOFLAGS: -mod=mod
+  checks: gofmt
+`, `# project settings
+merge-method: squash
+labels: [a] # default labels
+lint:
+  env:
+    GOFLAGS: -mod=mod
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		if err := Set("project", "labels", "b,c"); err != nil {
+			t.Fatalf("Set project failed: %v", err)
+		}
+		if err := Set("project", "lint-checks", "govet"); err != nil {
+			t.Fatalf("Set project failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(testRepo.Dir, ProjectConfigFile))
+		if err != nil {
+			t.Fatalf("Failed to read project config: %v", err)
+		}
+		expected := `# project settings
+merge-method: squash
+labels: [b, c] # default labels
+lint:
+  env:
+    GOFLAGS: -mod=mod
+  checks:
+    - govet
+`
+		if string(content) != expected {
+			t.Errorf("Expected only labels and lint.checks to change, got:\n%s", content)
+		}
+
+		if err := Set("user", "reviewers", "bob"); err != nil {
+			t.Fatalf("Set user failed: %v", err)
+		}
+		content, err = os.ReadFile(filepath.Join(os.Getenv("HOME"), ".config", "git-review.yaml"))
+		if err != nil {
+			t.Fatalf("Failed to read user config: %v", err)
+		}
+		for _, want := range []string{"# shared settings\nReviewers: [bob]\n", "# environment of the hooks", "GOFLAGS: -mod=mod"} {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("Expected the user config to contain %q, got:\n%s", want, content)
+			}
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	testRepo := newConfigTestRepo(t, `
+labels: [ok]
+colour: blue
+`, `
+open-browser: false
+merge-method: fast-forward
+`)
+	defer testRepo.Cleanup()
+
+	testRepo.GitExec("config", "review.ticket-title-prefix", "maybe")
+
+	testRepo.InDir(func() {
+		viper.Reset()
+		InitConfig()
+
+		problems := strings.Join(Validate(), "\n")
+		for _, expected := range []string{
+			`unknown key "colour"`,
+			`ignoring "open-browser"`,
+			`invalid boolean review.ticket-title-prefix="maybe"`,
+			`invalid merge-method "fast-forward"`,
+		} {
+			if !strings.Contains(problems, expected) {
+				t.Errorf("Expected problem %q, got:\n%s", expected, problems)
+			}
+		}
+	})
+}
@@ -6,12 +6,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v71/github"
 	keychain "github.com/jtamagnan/git-utils/keychain/lib"
 )
 
+// loggingTransport logs every GitHub API request at debug level with its duration
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", req.URL.String(), "duration", time.Since(start)}
+	if resp != nil {
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Debug("github api", attrs...)
+	return resp, err
+}
+
+// newHTTPClient returns an HTTP client that logs its requests
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: loggingTransport{base: http.DefaultTransport}}
+}
+
 // newAuthenticatedClient creates a GitHub client with token authentication
 func newAuthenticatedClient() (*github.Client, error) {
 	token, err := keychain.GetGitHubToken()
@@ -19,7 +46,7 @@ func newAuthenticatedClient() (*github.Client, error) {
 		return nil, err
 	}
 
-	return github.NewClient(nil).WithAuthToken(token), nil
+	return github.NewClient(newHTTPClient()).WithAuthToken(token), nil
 }
 
 // GetRemoteBranchFromPR gets the remote branch name from an existing PR
@@ -103,8 +130,229 @@ func RequestReviewers(owner, repo string, prNumber int, reviewers []string) erro
 	return nil
 }
 
-// EnableAutoMerge enables automerge for a pull request using GitHub's GraphQL API
-func EnableAutoMerge(owner, repo string, prNumber int) error {
+// resolveAssignees replaces "@me" with the login of the authenticated user
+func resolveAssignees(client *github.Client, assignees []string) ([]string, error) {
+	var resolved []string
+	for _, assignee := range assignees {
+		if assignee != "@me" {
+			resolved = append(resolved, assignee)
+			continue
+		}
+
+		user, _, err := client.Users.Get(context.Background(), "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up authenticated user for @me: %v", err)
+		}
+		resolved = append(resolved, user.GetLogin())
+	}
+	return resolved, nil
+}
+
+// AddAssignees assigns users to an issue or pull request. "@me" refers to the authenticated user.
+func AddAssignees(owner, repo string, issueNumber int, assignees []string) error {
+	if len(assignees) == 0 {
+		return nil // Nothing to do
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveAssignees(client, assignees)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Issues.AddAssignees(context.Background(), owner, repo, issueNumber, resolved)
+	if err != nil {
+		return fmt.Errorf("failed to add assignees to issue #%d: %v", issueNumber, err)
+	}
+
+	return nil
+}
+
+// SetMilestone sets the milestone of an issue or pull request, looking the milestone up by title
+func SetMilestone(owner, repo string, issueNumber int, title string) error {
+	if title == "" {
+		return nil // Nothing to do
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	opts := &github.MilestoneListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var milestoneNumber int
+	for milestoneNumber == 0 {
+		milestones, resp, err := client.Issues.ListMilestones(context.Background(), owner, repo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list milestones: %v", err)
+		}
+		for _, milestone := range milestones {
+			if strings.EqualFold(milestone.GetTitle(), title) {
+				milestoneNumber = milestone.GetNumber()
+				break
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if milestoneNumber == 0 {
+		return fmt.Errorf("no open milestone titled %q in %s/%s", title, owner, repo)
+	}
+
+	_, _, err = client.Issues.Edit(context.Background(), owner, repo, issueNumber, &github.IssueRequest{
+		Milestone: github.Ptr(milestoneNumber),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set milestone on issue #%d: %v", issueNumber, err)
+	}
+
+	return nil
+}
+
+// AddToProject adds a pull request to a Projects (v2) board, looking the project up by title
+// among the projects of the repository and of its owner
+func AddToProject(owner, repo string, prNumber int, title string) error {
+	if title == "" {
+		return nil // Nothing to do
+	}
+
+	query := `
+		query($owner: String!, $name: String!, $number: Int!, $title: String!) {
+			repository(owner: $owner, name: $name) {
+				pullRequest(number: $number) {
+					id
+				}
+				projectsV2(first: 100, query: $title) {
+					nodes {
+						id
+						title
+					}
+				}
+				owner {
+					... on ProjectV2Owner {
+						projectsV2(first: 100, query: $title) {
+							nodes {
+								id
+								title
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	type projectNodes struct {
+		Nodes []struct {
+			ID    string
+			Title string
+		}
+	}
+	var data struct {
+		Repository struct {
+			PullRequest *struct {
+				ID string
+			}
+			ProjectsV2 projectNodes
+			Owner      struct {
+				ProjectsV2 projectNodes
+			}
+		}
+	}
+
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"name":   repo,
+		"number": prNumber,
+		"title":  title,
+	}
+	if err := graphQL(query, variables, &data); err != nil {
+		return fmt.Errorf("failed to look up project %q: %v", title, err)
+	}
+
+	if data.Repository.PullRequest == nil {
+		return fmt.Errorf("PR #%d not found", prNumber)
+	}
+
+	var projectID string
+	candidates := append(data.Repository.ProjectsV2.Nodes, data.Repository.Owner.ProjectsV2.Nodes...)
+	for _, project := range candidates {
+		if strings.EqualFold(project.Title, title) {
+			projectID = project.ID
+			break
+		}
+	}
+	if projectID == "" {
+		return fmt.Errorf("no project titled %q found for %s/%s", title, owner, repo)
+	}
+
+	// Adding an item that is already on the project is a no-op
+	mutation := `
+		mutation($projectId: ID!, $contentId: ID!) {
+			addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
+				item {
+					id
+				}
+			}
+		}
+	`
+
+	variables = map[string]interface{}{
+		"projectId": projectID,
+		"contentId": data.Repository.PullRequest.ID,
+	}
+	if err := graphQL(mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to add PR #%d to project %q: %v", prNumber, title, err)
+	}
+
+	return nil
+}
+
+// RequestTeamReviewers requests reviews from teams, given as "org/team-slug" or "team-slug"
+func RequestTeamReviewers(owner, repo string, prNumber int, teams []string) error {
+	if len(teams) == 0 {
+		return nil // Nothing to do
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	// The API only takes the team slug; the organization is the repository owner
+	var slugs []string
+	for _, team := range teams {
+		if i := strings.LastIndex(team, "/"); i >= 0 {
+			team = team[i+1:]
+		}
+		slugs = append(slugs, team)
+	}
+
+	reviewersRequest := github.ReviewersRequest{
+		TeamReviewers: slugs,
+	}
+
+	_, _, err = client.PullRequests.RequestReviewers(context.Background(), owner, repo, prNumber, reviewersRequest)
+	if err != nil {
+		return fmt.Errorf("failed to request team reviewers for PR #%d: %v", prNumber, err)
+	}
+
+	return nil
+}
+
+// EnableAutoMerge enables automerge for a pull request using GitHub's GraphQL
+// API. mergeMethod is "merge", "squash" or "rebase".
+func EnableAutoMerge(owner, repo string, prNumber int, mergeMethod string) error {
 	client, err := newAuthenticatedClient()
 	if err != nil {
 		return err
@@ -121,11 +369,6 @@ func EnableAutoMerge(owner, repo string, prNumber int) error {
 	}
 
 	// Step 2: Enable auto-merge using GraphQL mutation
-	token, err := keychain.GetGitHubToken()
-	if err != nil {
-		return err
-	}
-
 	mutation := `
 		mutation($pullRequestId: ID!, $mergeMethod: PullRequestMergeMethod!) {
 			enablePullRequestAutoMerge(input: {
@@ -143,12 +386,25 @@ func EnableAutoMerge(owner, repo string, prNumber int) error {
 		}
 	`
 
+	variables := map[string]interface{}{
+		"pullRequestId": *pr.NodeID,
+		"mergeMethod":   strings.ToUpper(mergeMethod),
+	}
+
+	return graphQL(mutation, variables, nil)
+}
+
+// graphQL executes a query or mutation against GitHub's GraphQL API and
+// decodes the "data" field of the response into out (if out is non-nil)
+func graphQL(query string, variables map[string]interface{}, out interface{}) error {
+	token, err := keychain.GetGitHubToken()
+	if err != nil {
+		return err
+	}
+
 	requestBody := map[string]interface{}{
-		"query": mutation,
-		"variables": map[string]interface{}{
-			"pullRequestId": *pr.NodeID,
-			"mergeMethod":   "MERGE",
-		},
+		"query":     query,
+		"variables": variables,
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -164,8 +420,7 @@ func EnableAutoMerge(owner, repo string, prNumber int) error {
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := newHTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute GraphQL request: %v", err)
 	}
@@ -184,17 +439,7 @@ func EnableAutoMerge(owner, repo string, prNumber int) error {
 
 	// Parse response to check for GraphQL errors
 	var graphQLResponse struct {
-		Data struct {
-			EnablePullRequestAutoMerge struct {
-				PullRequest struct {
-					ID               string
-					AutoMergeRequest struct {
-						MergeMethod string
-						EnabledAt   string
-					}
-				}
-			}
-		}
+		Data   json.RawMessage
 		Errors []struct {
 			Message string
 		}
@@ -209,6 +454,13 @@ func EnableAutoMerge(owner, repo string, prNumber int) error {
 		return fmt.Errorf("GraphQL errors: %s", graphQLResponse.Errors[0].Message)
 	}
 
+	if out != nil && len(graphQLResponse.Data) > 0 {
+		err = json.Unmarshal(graphQLResponse.Data, out)
+		if err != nil {
+			return fmt.Errorf("failed to parse GraphQL data: %v", err)
+		}
+	}
+
 	return nil
 }
 
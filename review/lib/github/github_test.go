@@ -94,3 +94,16 @@ func TestCreatePRWithLabels(t *testing.T) {
 		t.Logf("Expected authentication error when no valid token: %v", err)
 	}
 }
+
+func TestPRMetadataNoOpWhenEmpty(t *testing.T) {
+	// Empty values must return before any API call is attempted
+	if err := AddAssignees("owner", "repo", 1, nil); err != nil {
+		t.Errorf("Expected no error for empty assignees, got: %v", err)
+	}
+	if err := SetMilestone("owner", "repo", 1, ""); err != nil {
+		t.Errorf("Expected no error for empty milestone, got: %v", err)
+	}
+	if err := AddToProject("owner", "repo", 1, ""); err != nil {
+		t.Errorf("Expected no error for empty project, got: %v", err)
+	}
+}
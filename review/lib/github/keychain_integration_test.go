@@ -10,6 +10,10 @@ import (
 
 // TestKeychainIntegrationWorkflow demonstrates the complete authentication workflow
 func TestKeychainIntegrationWorkflow(t *testing.T) {
+	// Only the keychain and GITHUB_TOKEN are expected to hold a token
+	t.Setenv("GIT_KEYCHAIN_SOURCES", "keychain,env")
+	t.Setenv("GH_TOKEN", "")
+
 	// Save original GITHUB_TOKEN
 	originalToken := os.Getenv("GITHUB_TOKEN")
 	defer func() {
@@ -0,0 +1,172 @@
+package github
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReviewComment is a single comment inside a review thread
+type ReviewComment struct {
+	Author         string
+	Body           string
+	URL            string
+	CreatedAt      time.Time
+	OriginalCommit string // commit the comment was originally made against
+}
+
+// ReviewThread is a conversation attached to a line of a file in a pull request
+type ReviewThread struct {
+	Path         string
+	Line         int    // line in the current PR head, 0 if the thread is outdated
+	OriginalLine int    // line in the commit the thread was started on
+	DiffSide     string // "RIGHT" for the new version of the file, "LEFT" for the base
+	IsResolved   bool
+	IsOutdated   bool
+	Comments     []ReviewComment
+}
+
+// PRReviewThreads holds the review threads of a pull request together with the
+// commits its line numbers refer to
+type PRReviewThreads struct {
+	Number  int
+	HeadOID string
+	BaseOID string
+	Threads []ReviewThread
+}
+
+const reviewThreadsQuery = `
+	query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+		repository(owner: $owner, name: $name) {
+			pullRequest(number: $number) {
+				headRefOid
+				baseRefOid
+				reviewThreads(first: 100, after: $cursor) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						path
+						line
+						originalLine
+						diffSide
+						isResolved
+						isOutdated
+						comments(first: 100) {
+							nodes {
+								author {
+									login
+								}
+								body
+								url
+								createdAt
+								originalCommit {
+									oid
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+`
+
+// GetReviewThreads fetches all review threads of a pull request
+func GetReviewThreads(owner, repo string, prNumber int) (*PRReviewThreads, error) {
+	result := &PRReviewThreads{Number: prNumber}
+
+	var cursor *string
+	for {
+		var data struct {
+			Repository struct {
+				PullRequest *struct {
+					HeadRefOid    string
+					BaseRefOid    string
+					ReviewThreads struct {
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   string
+						}
+						Nodes []struct {
+							Path         string
+							Line         *int
+							OriginalLine *int
+							DiffSide     string
+							IsResolved   bool
+							IsOutdated   bool
+							Comments     struct {
+								Nodes []struct {
+									Author *struct {
+										Login string
+									}
+									Body           string
+									URL            string
+									CreatedAt      time.Time
+									OriginalCommit *struct {
+										Oid string
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+
+		variables := map[string]interface{}{
+			"owner":  owner,
+			"name":   repo,
+			"number": prNumber,
+			"cursor": cursor,
+		}
+		if err := graphQL(reviewThreadsQuery, variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to get review threads for PR #%d: %v", prNumber, err)
+		}
+
+		pr := data.Repository.PullRequest
+		if pr == nil {
+			return nil, fmt.Errorf("PR #%d not found", prNumber)
+		}
+		result.HeadOID = pr.HeadRefOid
+		result.BaseOID = pr.BaseRefOid
+
+		for _, node := range pr.ReviewThreads.Nodes {
+			thread := ReviewThread{
+				Path:       node.Path,
+				DiffSide:   node.DiffSide,
+				IsResolved: node.IsResolved,
+				IsOutdated: node.IsOutdated,
+			}
+			if node.Line != nil {
+				thread.Line = *node.Line
+			}
+			if node.OriginalLine != nil {
+				thread.OriginalLine = *node.OriginalLine
+			}
+			for _, c := range node.Comments.Nodes {
+				comment := ReviewComment{
+					Body:      c.Body,
+					URL:       c.URL,
+					CreatedAt: c.CreatedAt,
+				}
+				if c.Author != nil {
+					comment.Author = c.Author.Login
+				}
+				if c.OriginalCommit != nil {
+					comment.OriginalCommit = c.OriginalCommit.Oid
+				}
+				thread.Comments = append(thread.Comments, comment)
+			}
+			result.Threads = append(result.Threads, thread)
+		}
+
+		if !pr.ReviewThreads.PageInfo.HasNextPage {
+			break
+		}
+		endCursor := pr.ReviewThreads.PageInfo.EndCursor
+		cursor = &endCursor
+	}
+
+	return result, nil
+}
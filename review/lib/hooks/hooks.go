@@ -0,0 +1,104 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Event identifies the point of a review or stack run at which a hook runs
+type Event string
+
+const (
+	PrePush    Event = "pre-push"    // before a branch is pushed, a failure aborts the run
+	PostCreate Event = "post-create" // after a pull request is created
+	PostUpdate Event = "post-update" // after an existing pull request is updated
+	PostStack  Event = "post-stack"  // after a whole stack has been created or updated
+)
+
+// Events lists every hook event
+var Events = []Event{PrePush, PostCreate, PostUpdate, PostStack}
+
+// Setting returns the name of the setting that configures the hook for event
+func Setting(event Event) string {
+	return string(event) + "-hook"
+}
+
+// Hooks maps events to the shell command run for them. Events without a
+// command are skipped.
+type Hooks map[Event]string
+
+// Payload describes the pull request a hook runs for. It is written as JSON
+// to the hook's stdin and exported as GIT_REVIEW_* environment variables.
+type Payload struct {
+	Event    Event     `json:"event,omitempty"`
+	PRNumber int       `json:"pr_number,omitempty"`
+	PRURL    string    `json:"pr_url,omitempty"`
+	Remote   string    `json:"remote,omitempty"`
+	Branch   string    `json:"branch,omitempty"` // remote branch of the pull request
+	Base     string    `json:"base,omitempty"`   // branch the pull request targets
+	From     string    `json:"from,omitempty"`   // commit the range starts after
+	To       string    `json:"to,omitempty"`     // last commit of the range
+	PRs      []Payload `json:"prs,omitempty"`    // every pull request of the stack, for post-stack
+}
+
+// Env returns the payload as environment variables
+func (p Payload) Env() []string {
+	env := []string{
+		"GIT_REVIEW_EVENT=" + string(p.Event),
+		"GIT_REVIEW_REMOTE=" + p.Remote,
+		"GIT_REVIEW_BRANCH=" + p.Branch,
+		"GIT_REVIEW_BASE=" + p.Base,
+		"GIT_REVIEW_FROM=" + p.From,
+		"GIT_REVIEW_TO=" + p.To,
+	}
+	if p.From != "" && p.To != "" {
+		env = append(env, "GIT_REVIEW_RANGE="+p.From+".."+p.To)
+	}
+	if p.PRNumber > 0 {
+		env = append(env, "GIT_REVIEW_PR_NUMBER="+strconv.Itoa(p.PRNumber))
+	}
+	if p.PRURL != "" {
+		env = append(env, "GIT_REVIEW_PR_URL="+p.PRURL)
+	}
+	return env
+}
+
+// Run runs the hook configured for event with sh, passing the payload on
+// stdin and in the environment and printing its output to out. It returns an
+// error if the hook exits with a non-zero status.
+func (h Hooks) Run(event Event, payload Payload, out io.Writer) error {
+	command := h[event]
+	if command == "" {
+		return nil
+	}
+
+	payload.Event = event
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s hook payload: %v", event, err)
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), payload.Env()...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %v", event, err)
+	}
+	return nil
+}
+
+// RunPost runs a post-* hook. Failures are only reported as warnings since
+// the pull requests already exist.
+func (h Hooks) RunPost(event Event, payload Payload, out io.Writer) {
+	if err := h.Run(event, payload, out); err != nil {
+		slog.Warn("hook failed", "event", event, "err", err)
+	}
+}
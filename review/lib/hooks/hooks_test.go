@@ -0,0 +1,88 @@
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPassesPayload(t *testing.T) {
+	dir := t.TempDir()
+	stdinFile := filepath.Join(dir, "stdin.json")
+	envFile := filepath.Join(dir, "env")
+
+	h := Hooks{
+		PostCreate: "cat > " + stdinFile + " && env | grep ^GIT_REVIEW_ > " + envFile,
+	}
+	payload := Payload{
+		PRNumber: 42,
+		PRURL:    "https://github.com/acme/app/pull/42",
+		Remote:   "origin",
+		Branch:   "alice/pr/1234",
+		Base:     "main",
+		From:     "abc123",
+		To:       "def456",
+	}
+
+	if err := h.Run(PostCreate, payload, io.Discard); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	content, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatalf("Failed to read hook stdin: %v", err)
+	}
+	var received Payload
+	if err := json.Unmarshal(content, &received); err != nil {
+		t.Fatalf("Hook stdin is not valid JSON: %v\n%s", err, content)
+	}
+	payload.Event = PostCreate
+	if received.Event != payload.Event || received.PRNumber != payload.PRNumber || received.To != payload.To {
+		t.Errorf("Expected payload %+v, got %+v", payload, received)
+	}
+
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Failed to read hook env: %v", err)
+	}
+	for _, expected := range []string{
+		"GIT_REVIEW_EVENT=post-create",
+		"GIT_REVIEW_PR_NUMBER=42",
+		"GIT_REVIEW_PR_URL=https://github.com/acme/app/pull/42",
+		"GIT_REVIEW_BRANCH=alice/pr/1234",
+		"GIT_REVIEW_BASE=main",
+		"GIT_REVIEW_RANGE=abc123..def456",
+	} {
+		if !strings.Contains(string(env), expected+"\n") {
+			t.Errorf("Expected %s in hook environment, got:\n%s", expected, env)
+		}
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   Hooks
+		wantErr bool
+	}{
+		{name: "no hook configured", hooks: Hooks{}, wantErr: false},
+		{name: "hook succeeds", hooks: Hooks{PrePush: "true"}, wantErr: false},
+		{name: "hook fails", hooks: Hooks{PrePush: "exit 3"}, wantErr: true},
+		{name: "other event configured", hooks: Hooks{PostStack: "exit 1"}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hooks.Run(PrePush, Payload{}, io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "pre-push hook failed") {
+				t.Errorf("Expected the error to name the hook, got %v", err)
+			}
+		})
+	}
+}
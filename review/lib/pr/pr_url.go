@@ -31,11 +31,11 @@ func DetectExistingPR(repo *git.Repository, upstreamBranch string) (int, error)
 
 // StackCommitPR holds the PR info extracted from a single commit
 type StackCommitPR struct {
-	Hash     string
-	Summary  string
-	PRURL    string // empty if no PR URL found
-	PRNum    int    // 0 if no PR URL found
-	WantsPR  bool   // true if commit has a bare "PR URL:" sentinel (requests a new PR)
+	Hash    string
+	Summary string
+	PRURL   string // empty if no PR URL found
+	PRNum   int    // 0 if no PR URL found
+	WantsPR bool   // true if commit has a bare "PR URL:" sentinel (requests a new PR)
 }
 
 // DetectAllPRs returns per-commit PR info for all commits between parent and HEAD (oldest first)
@@ -0,0 +1,96 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jtamagnan/git-utils/git"
+)
+
+// Action describes what a run did to a pull request
+type Action string
+
+const (
+	Created   Action = "created"
+	Updated   Action = "updated"
+	Unchanged Action = "unchanged"
+)
+
+// PRResult describes one pull request handled by review, stack or status
+type PRResult struct {
+	Number  int      `json:"number"` // 0 if the commits have no pull request yet
+	URL     string   `json:"url"`
+	Head    string   `json:"head"`             // remote branch of the pull request
+	Base    string   `json:"base"`             // branch the pull request targets
+	Commits []string `json:"commits"`          // commit SHAs, oldest first
+	Action  Action   `json:"action,omitempty"` // set by review and stack
+	State   string   `json:"state,omitempty"`  // set by status: open, closed or merged
+}
+
+// Result is the machine-readable result of review, stack and status
+type Result struct {
+	Remote       string     `json:"remote"`
+	PullRequests []PRResult `json:"pull_requests"`
+}
+
+// WriteJSON writes the result as indented JSON
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the result as a table
+func (r *Result) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PR\tSTATE\tHEAD\tBASE\tCOMMITS\tURL")
+	for _, p := range r.PullRequests {
+		number, state := "-", p.State
+		if p.Number > 0 {
+			number = fmt.Sprintf("#%d", p.Number)
+		}
+		if state == "" {
+			state = string(p.Action)
+		}
+		if p.Number == 0 {
+			state = "no PR"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", number, state, p.Head, p.Base, len(p.Commits), p.URL)
+	}
+	return tw.Flush()
+}
+
+// groupResult describes the pull request of a stack group
+func groupResult(group stackGroup, action Action) PRResult {
+	result := PRResult{
+		Number:  group.prNumber,
+		URL:     group.prURL,
+		Head:    group.branchName,
+		Base:    group.baseBranch,
+		Commits: []string{},
+		Action:  action,
+	}
+	for _, c := range group.commits {
+		result.Commits = append(result.Commits, c.Hash)
+	}
+	return result
+}
+
+// forcePush force pushes refspec to the remote and reports whether the
+// remote branch changed
+func forcePush(repo *git.Repository, remote, refspec string) (bool, error) {
+	out, err := repo.GitExec("push", "--force", "--porcelain", remote, refspec)
+	if err != nil {
+		return false, err
+	}
+	// In porcelain output, "=" flags a ref that was already up to date
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "=\t") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
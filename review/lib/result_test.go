@@ -0,0 +1,111 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jtamagnan/git-utils/git"
+	"github.com/jtamagnan/git-utils/review/lib/pr"
+)
+
+func TestForcePushReportsChanges(t *testing.T) {
+	remoteDir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", remoteDir).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create bare remote: %v\n%s", err, out)
+	}
+
+	testRepo := git.NewTestRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.AddCommit("file.txt", "content", "Initial commit")
+	testRepo.GitExec("remote", "add", "origin", remoteDir)
+
+	steps := []struct {
+		name    string
+		commit  bool
+		changed bool
+	}{
+		{name: "new branch", commit: false, changed: true},
+		{name: "same commit", commit: false, changed: false},
+		{name: "new commit", commit: true, changed: true},
+	}
+
+	testRepo.InDir(func() {
+		for _, step := range steps {
+			if step.commit {
+				testRepo.AddCommit("file.txt", "changed", "Change file")
+			}
+			changed, err := forcePush(testRepo.Repo, "origin", "HEAD:refs/heads/feature")
+			if err != nil {
+				t.Fatalf("%s: forcePush failed: %v", step.name, err)
+			}
+			if changed != step.changed {
+				t.Errorf("%s: expected changed=%v, got %v", step.name, step.changed, changed)
+			}
+		}
+	})
+}
+
+func TestResultJSONSchema(t *testing.T) {
+	group := stackGroup{
+		commits:    []pr.StackCommitPR{{Hash: "aaa"}, {Hash: "bbb"}},
+		prNumber:   7,
+		prURL:      "https://github.com/acme/app/pull/7",
+		branchName: "alice/pr/1",
+		baseBranch: "main",
+	}
+	result := &Result{Remote: "origin", PullRequests: []PRResult{groupResult(group, Updated)}}
+
+	var out bytes.Buffer
+	if err := result.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out.String())
+	}
+	expected := map[string]interface{}{
+		"remote": "origin",
+		"pull_requests": []interface{}{
+			map[string]interface{}{
+				"number":  float64(7),
+				"url":     "https://github.com/acme/app/pull/7",
+				"head":    "alice/pr/1",
+				"base":    "main",
+				"commits": []interface{}{"aaa", "bbb"},
+				"action":  "updated",
+			},
+		},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected %v, got %v", expected, decoded)
+	}
+}
+
+func TestResultWriteText(t *testing.T) {
+	result := &Result{Remote: "origin", PullRequests: []PRResult{
+		{Number: 7, URL: "https://github.com/acme/app/pull/7", Head: "alice/pr/1", Base: "main", Commits: []string{"aaa"}, State: "open"},
+		{Commits: []string{"bbb", "ccc"}},
+	}}
+
+	var out bytes.Buffer
+	if err := result.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); !reflect.DeepEqual(fields, []string{"#7", "open", "alice/pr/1", "main", "1", "https://github.com/acme/app/pull/7"}) {
+		t.Errorf("Unexpected row for PR #7: %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "-") || !strings.Contains(lines[2], "no PR") {
+		t.Errorf("Expected commits without a PR to be listed, got %q", lines[2])
+	}
+}
@@ -2,9 +2,14 @@ package review
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-github/v71/github"
 	"github.com/jtamagnan/git-utils/editor"
 	"github.com/jtamagnan/git-utils/git"
@@ -12,9 +17,11 @@ import (
 	"github.com/jtamagnan/git-utils/review/lib/branch"
 	"github.com/jtamagnan/git-utils/review/lib/commit"
 	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+	"github.com/jtamagnan/git-utils/review/lib/hooks"
 	"github.com/jtamagnan/git-utils/review/lib/parent"
 	"github.com/jtamagnan/git-utils/review/lib/pr"
 	"github.com/jtamagnan/git-utils/review/lib/template"
+	"github.com/jtamagnan/git-utils/review/lib/ticket"
 )
 
 // ParsedArgs represents the parsed command line arguments
@@ -25,8 +32,35 @@ type ParsedArgs struct {
 	AutoMerge   bool
 	Labels      []string
 	Reviewers   []string
+	Assignees   []string
+	Milestone   string
+	Project     string
 	Verbose     bool
 	Parent      string
+
+	TeamReviewers []string
+	BranchName    string
+	BranchPrefix  string
+	Template      string
+	MergeMethod   string
+	LintChecks    []string
+	LintSkip      []string // hooks to skip, passed as SKIP
+	Autofix       bool
+	IsolatedLint  bool // lint HEAD in a temporary worktree
+	Hooks         hooks.Hooks
+	Output        io.Writer // where progress is printed, stdout if nil
+
+	TicketTrackers    []string
+	TicketTitlePrefix bool
+	TicketRequired    string
+}
+
+// output returns w, or stdout if w is nil
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
 }
 
 // stripRemotePrefix removes the specific remote prefix from branch names (e.g., "origin/main" -> "main")
@@ -40,48 +74,111 @@ func stripRemotePrefix(branch, remote string) string {
 }
 
 // getPRDescription gets the initial PR description content from templates and opens editor
-func getPRDescription() (string, error) {
+func getPRDescription(repo *git.Repository, templatePath string) (string, error) {
 	// Get the initial template content
-	initialContent := template.FindPRTemplate()
+	initialContent, err := loadPRTemplate(repo, templatePath)
+	if err != nil {
+		return "", err
+	}
 
 	// Open editor with the template content for user to edit
 	return editor.OpenEditor(initialContent)
 }
 
+// loadPRTemplate loads the configured PR template, resolving relative paths
+// against the repository root
+func loadPRTemplate(repo *git.Repository, templatePath string) (string, error) {
+	if templatePath != "" && !filepath.IsAbs(templatePath) {
+		if workTree, err := repo.Worktree(); err == nil {
+			templatePath = filepath.Join(workTree.Filesystem.Root(), templatePath)
+		}
+	}
+	return template.LoadPRTemplate(templatePath)
+}
+
+// newRemoteBranchName generates a remote branch name for a new PR from the
+// configured template, using the oldest commit summary for {slug}
+func newRemoteBranchName(repo *git.Repository, upstream, parentBranch string, tickets []ticket.Ticket, args ParsedArgs) (string, error) {
+	data := branch.NameData{
+		Prefix: args.BranchPrefix,
+		Ticket: ticket.BranchKey(tickets),
+	}
+	if summaries := repo.RefSummaries(parentBranch); len(summaries) > 0 {
+		data.Summary = summaries[0]
+	}
+	return branch.GenerateName(repo, upstream, args.BranchName, data)
+}
+
 // cleanupRemoteBranch deletes a remote branch if it was created for a new PR
-func cleanupRemoteBranch(repo *git.Repository, upstream, remoteBranchName string) {
-	fmt.Printf("Cleaning up remote branch: %s\n", remoteBranchName)
+func cleanupRemoteBranch(out io.Writer, repo *git.Repository, upstream, remoteBranchName string) {
+	fmt.Fprintf(out, "Cleaning up remote branch: %s\n", remoteBranchName)
 
 	// Delete the remote branch
 	_, cleanupErr := repo.GitExec("push", upstream, "--delete", remoteBranchName)
 	if cleanupErr != nil {
-		fmt.Printf("Warning: Failed to delete remote branch %s: %v\n", remoteBranchName, cleanupErr)
+		slog.Warn("failed to delete remote branch", "branch", remoteBranchName, "err", cleanupErr)
 	} else {
-		fmt.Printf("Successfully deleted remote branch: %s\n", remoteBranchName)
+		fmt.Fprintf(out, "Successfully deleted remote branch: %s\n", remoteBranchName)
+	}
+}
+
+// currentBranchName returns the short name of the checked out branch, or "" if HEAD is detached
+func currentBranchName(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+	return head.Name().Short()
+}
+
+// commitMessages returns the full commit messages of the given commits
+func commitMessages(repo *git.Repository, hashes []string) []string {
+	var messages []string
+	for _, hash := range hashes {
+		message, err := repo.GitExec("log", "-1", "--pretty=format:%B", hash)
+		if err == nil {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// commitRange resolves the commits after the merge base of from and to, up to
+// to, for hook payloads. Unresolvable refs are returned as given.
+func commitRange(repo *git.Repository, from, to string) (string, string) {
+	if base, err := repo.GitExec("merge-base", from, to); err == nil {
+		from = base
+	}
+	if hash, err := repo.GitExec("rev-parse", to); err == nil {
+		to = hash
+	}
+	return from, to
+}
+
+// applyPRMetadata sets the assignees, milestone and project of a PR. Failures
+// are reported as warnings since the PR itself already exists.
+func applyPRMetadata(repoInfo *git.RepositoryInfo, prNumber int, assignees []string, milestone, project string) {
+	if err := githubapi.AddAssignees(repoInfo.Owner, repoInfo.Name, prNumber, assignees); err != nil {
+		slog.Warn("failed to set assignees", "pr", prNumber, "err", err)
+	}
+	if err := githubapi.SetMilestone(repoInfo.Owner, repoInfo.Name, prNumber, milestone); err != nil {
+		slog.Warn("failed to set milestone", "pr", prNumber, "err", err)
+	}
+	if err := githubapi.AddToProject(repoInfo.Owner, repoInfo.Name, prNumber, project); err != nil {
+		slog.Warn("failed to add to project", "pr", prNumber, "err", err)
 	}
 }
 
 // Review performs the main review workflow
-func Review(args ParsedArgs) error {
+func Review(args ParsedArgs) (*Result, error) {
+	out := output(args.Output)
+
 	//
 	// Get current repository
 	//
 	repo, err := git.GetRepository()
 	if err != nil {
-		return err
-	}
-
-	//
-	// Run pre-commit checks unless skipped
-	//
-	if args.NoVerify {
-		fmt.Println("Skipping pre-commit checks")
-	} else {
-		fmt.Println("Running pre-commit checks...")
-		err = lint.Lint(lint.ParsedArgs{Stream: args.Verbose})
-		if err != nil {
-			return err
-		}
+		return nil, err
 	}
 
 	//
@@ -89,7 +186,7 @@ func Review(args ParsedArgs) error {
 	//
 	upstream, err := repo.Remote()
 	if err != nil {
-		return fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+		return nil, fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
 	}
 
 	//
@@ -97,12 +194,12 @@ func Review(args ParsedArgs) error {
 	//
 	upstreamURL, err := repo.GetRemoteURL(upstream)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	repoInfo, err := git.ParseRepositoryInfo(upstreamURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	//
@@ -110,11 +207,42 @@ func Review(args ParsedArgs) error {
 	//
 	resolvedParent, err := parent.ResolveParent(repo, args.Parent, repoInfo.Owner, repoInfo.Name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	parentBranch := resolvedParent.GitRef
-	fmt.Printf("Using parent branch: %s (GitHub base: %s)\n", parentBranch, resolvedParent.GitHubBase)
+	fmt.Fprintf(out, "Using parent branch: %s (GitHub base: %s)\n", parentBranch, resolvedParent.GitHubBase)
+
+	//
+	// Run pre-commit checks unless skipped
+	//
+	if args.NoVerify {
+		fmt.Fprintln(out, "Skipping pre-commit checks")
+	} else {
+		fmt.Fprintln(out, "Running pre-commit checks...")
+		err = lint.Lint(lint.ParsedArgs{
+			Stream:     args.Verbose,
+			CheckNames: args.LintChecks,
+			Autofix:    args.Autofix,
+			FromRef:    parentBranch,
+			Isolated:   args.IsolatedLint,
+			Skip:       args.LintSkip,
+			Output:     out,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//
+	// Find ticket references in the branch name and commit messages
+	//
+	ticketConfig, err := ticket.NewConfig(args.TicketTrackers, args.TicketTitlePrefix, args.TicketRequired)
+	if err != nil {
+		return nil, err
+	}
+	messages := git.RefExec(repo, func(c *object.Commit) string { return c.Message }, parentBranch)
+	tickets := ticket.Detect(ticketConfig, currentBranchName(repo), messages)
 
 	//
 	// Determine the remote branch name to use and if the PR already
@@ -124,46 +252,83 @@ func Review(args ParsedArgs) error {
 	var isNewPR bool
 	existingPRNumber, err := pr.DetectExistingPR(repo, parentBranch)
 	if err != nil {
-		// No existing PR found, generate UUID branch name for new PR
-		remoteBranchName, err = branch.GenerateUUIDBranchName()
+		// No existing PR found, generate a branch name for the new PR
+		remoteBranchName, err = newRemoteBranchName(repo, upstream, parentBranch, tickets, args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		isNewPR = true
-		fmt.Printf("No existing PR found, will create new PR with branch: %s\n", remoteBranchName)
+		fmt.Fprintf(out, "No existing PR found, will create new PR with branch: %s\n", remoteBranchName)
 	} else {
 		// Check if the existing PR is still open
 		existingPR, err := githubapi.GetExistingPR(repoInfo.Owner, repoInfo.Name, existingPRNumber)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if existingPR.State != nil && *existingPR.State == "open" {
 			// Existing open PR found, get the remote branch name from the PR
 			remoteBranchName, err = githubapi.GetRemoteBranchFromPR(repoInfo.Owner, repoInfo.Name, existingPRNumber)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			isNewPR = false
-			fmt.Printf("Found existing open PR #%d, will update branch: %s\n", existingPRNumber, remoteBranchName)
+			fmt.Fprintf(out, "Found existing open PR #%d, will update branch: %s\n", existingPRNumber, remoteBranchName)
 		} else {
 			// Existing PR is closed, create a new PR
-			remoteBranchName, err = branch.GenerateUUIDBranchName()
+			remoteBranchName, err = newRemoteBranchName(repo, upstream, parentBranch, tickets, args)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			isNewPR = true
-			fmt.Printf("Found existing PR #%d but it's closed, will create new PR with branch: %s\n", existingPRNumber, remoteBranchName)
+			fmt.Fprintf(out, "Found existing PR #%d but it's closed, will create new PR with branch: %s\n", existingPRNumber, remoteBranchName)
+		}
+	}
+
+	//
+	// New PRs must reference a ticket if the repository requires one
+	//
+	if isNewPR {
+		err = ticket.Enforce(ticketConfig.Required, tickets)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//
+	// Run the pre-push hook, it may refuse the push. Like git's own pre-push
+	// hook it is skipped by --no-verify.
+	//
+	hookPayload := hooks.Payload{
+		Remote: upstream,
+		Branch: remoteBranchName,
+		Base:   resolvedParent.GitHubBase,
+	}
+	if !isNewPR {
+		hookPayload.PRNumber = existingPRNumber
+	}
+	hookPayload.From, hookPayload.To = commitRange(repo, parentBranch, "HEAD")
+	if !args.NoVerify {
+		err = args.Hooks.Run(hooks.PrePush, hookPayload, out)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	//
 	// Push changes to the determined remote branch
 	//
-	fmt.Printf("Pushing to %s %s\n", upstream, remoteBranchName)
-	_, err = repo.GitExec("push", "--force", upstream, fmt.Sprintf("HEAD:%s", remoteBranchName))
+	fmt.Fprintf(out, "Pushing to %s %s\n", upstream, remoteBranchName)
+	pushed, err := forcePush(repo, upstream, fmt.Sprintf("HEAD:%s", remoteBranchName))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	action := Created
+	if !isNewPR {
+		action = Unchanged
+		if pushed {
+			action = Updated
+		}
 	}
 
 	// Set up cleanup for new PR branches in case of failure
@@ -171,7 +336,7 @@ func Review(args ParsedArgs) error {
 	if isNewPR {
 		defer func() {
 			if !prCreationSucceeded {
-				cleanupRemoteBranch(repo, upstream, remoteBranchName)
+				cleanupRemoteBranch(out, repo, upstream, remoteBranchName)
 			}
 		}()
 	}
@@ -186,34 +351,47 @@ func Review(args ParsedArgs) error {
 		//
 		summaries := repo.RefSummaries(parentBranch)
 		if len(summaries) == 0 {
-			return fmt.Errorf("no commits found between HEAD and %s", parentBranch)
+			return nil, fmt.Errorf("no commits found between HEAD and %s", parentBranch)
 		}
 		prTitle := summaries[0] // Use the oldest (first) commit summary
+		if ticketConfig.TitlePrefix {
+			prTitle = ticket.PrefixTitle(prTitle, tickets)
+		}
 
 		//
 		// Get the PR description
 		//
-		prDescription, err := getPRDescription()
+		prDescription, err := getPRDescription(repo, args.Template)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		prDescription = ticket.AddToBody(prDescription, tickets)
 
 		//
 		// Open the PR
 		//
 		githubPR, err = githubapi.CreatePR(repoInfo.Owner, repoInfo.Name, prTitle, remoteBranchName, resolvedParent.GitHubBase, prDescription, args.Draft, args.Labels, args.Reviewers)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		//
+		// Request team reviews, set assignees, milestone and project
+		//
+		err = githubapi.RequestTeamReviewers(repoInfo.Owner, repoInfo.Name, *githubPR.Number, args.TeamReviewers)
+		if err != nil {
+			slog.Warn("failed to request team reviewers", "pr", *githubPR.Number, "err", err)
+		}
+		applyPRMetadata(repoInfo, *githubPR.Number, args.Assignees, args.Milestone, args.Project)
+
 		//
 		// Enable auto-merge if requested
 		//
 		if args.AutoMerge {
-			fmt.Printf("Enabling auto-merge for PR #%d\n", *githubPR.Number)
-			err = githubapi.EnableAutoMerge(repoInfo.Owner, repoInfo.Name, *githubPR.Number)
+			fmt.Fprintf(out, "Enabling auto-merge for PR #%d\n", *githubPR.Number)
+			err = githubapi.EnableAutoMerge(repoInfo.Owner, repoInfo.Name, *githubPR.Number, args.MergeMethod)
 			if err != nil {
-				fmt.Printf("Warning: Failed to enable auto-merge: %v\n", err)
+				slog.Warn("failed to enable auto-merge", "pr", *githubPR.Number, "err", err)
 				// Don't fail the entire operation if auto-merge fails
 			}
 		}
@@ -222,33 +400,52 @@ func Review(args ParsedArgs) error {
 		// Mark PR creation as successful to prevent branch deletion
 		//
 		prCreationSucceeded = true
-		fmt.Printf("Created new PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
+		fmt.Fprintf(out, "Created new PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
 
 		//
 		// Update the oldest commit message with the PR URL
 		//
-		err = commit.UpdateOldestCommitWithPRURL(repo, parentBranch, *githubPR.HTMLURL)
+		err = commit.UpdateOldestCommitWithPRURL(out, repo, parentBranch, *githubPR.HTMLURL)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		//
 		// Push again with the updated commit message
 		//
-		fmt.Printf("Pushing updated commits to %s %s\n", upstream, remoteBranchName)
+		fmt.Fprintf(out, "Pushing updated commits to %s %s\n", upstream, remoteBranchName)
 		_, err = repo.GitExec("push", "--force", upstream, fmt.Sprintf("HEAD:%s", remoteBranchName))
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		//
+		// Run the post-create hook on the pushed commits
+		//
+		hookPayload.PRNumber = *githubPR.Number
+		hookPayload.PRURL = *githubPR.HTMLURL
+		hookPayload.From, hookPayload.To = commitRange(repo, parentBranch, "HEAD")
+		args.Hooks.RunPost(hooks.PostCreate, hookPayload, out)
 	} else {
 		//
 		// Get the PR
 		//
-		fmt.Printf("Found existing PR #%d\n", existingPRNumber)
+		fmt.Fprintf(out, "Found existing PR #%d\n", existingPRNumber)
 		githubPR, err = githubapi.GetExistingPR(repoInfo.Owner, repoInfo.Name, existingPRNumber)
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		//
+		// Keep assignees, milestone and project in sync with the current settings
+		//
+		applyPRMetadata(repoInfo, existingPRNumber, args.Assignees, args.Milestone, args.Project)
+
+		//
+		// Run the post-update hook
+		//
+		hookPayload.PRURL = *githubPR.HTMLURL
+		args.Hooks.RunPost(hooks.PostUpdate, hookPayload, out)
 	}
 
 	//
@@ -257,14 +454,24 @@ func Review(args ParsedArgs) error {
 	if args.OpenBrowser {
 		err = exec.Command("open", *githubPR.HTMLURL).Run()
 		if err != nil {
-			fmt.Printf("Failed to open browser: %v\n", err)
+			fmt.Fprintf(out, "Failed to open browser: %v\n", err)
 		}
 	}
 
-	fmt.Printf("PR URL: %s\n", *githubPR.HTMLURL)
+	fmt.Fprintf(out, "PR URL: %s\n", *githubPR.HTMLURL)
 
 	//
 	// Clean exit to avoid any cleanup that might interfere with the PR
 	//
-	return nil
+	return &Result{
+		Remote: upstream,
+		PullRequests: []PRResult{{
+			Number:  *githubPR.Number,
+			URL:     *githubPR.HTMLURL,
+			Head:    remoteBranchName,
+			Base:    resolvedParent.GitHubBase,
+			Commits: git.RefExec(repo, func(c *object.Commit) string { return c.Hash.String() }, parentBranch),
+			Action:  action,
+		}},
+	}, nil
 }
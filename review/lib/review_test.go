@@ -127,7 +127,7 @@ func TestReviewNoUpstream(t *testing.T) {
 			Verbose:     false,
 		}
 
-		err := Review(args)
+		_, err := Review(args)
 		if err == nil {
 			t.Error("Expected error when no upstream is configured, but got none")
 		}
@@ -2,6 +2,8 @@ package review
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os/exec"
 	"strings"
 
@@ -12,9 +14,10 @@ import (
 	"github.com/jtamagnan/git-utils/review/lib/branch"
 	"github.com/jtamagnan/git-utils/review/lib/commit"
 	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+	"github.com/jtamagnan/git-utils/review/lib/hooks"
 	"github.com/jtamagnan/git-utils/review/lib/parent"
 	"github.com/jtamagnan/git-utils/review/lib/pr"
-	"github.com/jtamagnan/git-utils/review/lib/template"
+	"github.com/jtamagnan/git-utils/review/lib/ticket"
 )
 
 // StackParsedArgs represents the parsed command line arguments for the stack command
@@ -23,6 +26,25 @@ type StackParsedArgs struct {
 	OpenBrowser bool
 	Verbose     bool
 	Parent      string
+
+	BranchName   string
+	BranchPrefix string
+	Template     string
+	LintChecks   []string
+	LintSkip     []string // hooks to skip, passed as SKIP
+	PerCommit    bool     // lint each group on its own instead of the whole stack at once
+	Autofix      bool
+	IsolatedLint bool // lint HEAD in a temporary worktree
+	Hooks        hooks.Hooks
+	Output       io.Writer // where progress is printed, stdout if nil
+
+	TicketTrackers    []string
+	TicketTitlePrefix bool
+	TicketRequired    string
+
+	Assignees []string // set on every PR of the stack
+	Milestone string
+	Project   string
 }
 
 // stackGroup represents a group of commits that belong to one PR
@@ -32,58 +54,70 @@ type stackGroup struct {
 	prURL      string
 	branchName string // remote branch for this PR
 	baseBranch string // what this PR targets (GitHub base)
+	tickets    []ticket.Ticket
 }
 
 // Stack performs the stacked PR workflow
-func Stack(args StackParsedArgs) error {
+func Stack(args StackParsedArgs) (*Result, error) {
+	out := output(args.Output)
+
 	repo, err := git.GetRepository()
 	if err != nil {
-		return err
-	}
-
-	// Run pre-commit checks
-	if args.NoVerify {
-		fmt.Println("Skipping pre-commit checks")
-	} else {
-		fmt.Println("Running pre-commit checks...")
-		err = lint.Lint(lint.ParsedArgs{Stream: args.Verbose})
-		if err != nil {
-			return err
-		}
+		return nil, err
 	}
 
 	// Get upstream remote
 	upstream, err := repo.Remote()
 	if err != nil {
-		return fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+		return nil, fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
 	}
 
 	upstreamURL, err := repo.GetRemoteURL(upstream)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	repoInfo, err := git.ParseRepositoryInfo(upstreamURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Resolve parent branch
 	resolvedParent, err := parent.ResolveParent(repo, args.Parent, repoInfo.Owner, repoInfo.Name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	parentBranch := resolvedParent.GitRef
-	fmt.Printf("Using parent branch: %s (GitHub base: %s)\n", parentBranch, resolvedParent.GitHubBase)
+	fmt.Fprintf(out, "Using parent branch: %s (GitHub base: %s)\n", parentBranch, resolvedParent.GitHubBase)
+
+	// Run pre-commit checks. Per-commit checks need the groups and run once
+	// they are known.
+	if args.NoVerify {
+		fmt.Fprintln(out, "Skipping pre-commit checks")
+	} else if !args.PerCommit {
+		fmt.Fprintln(out, "Running pre-commit checks...")
+		err = lint.Lint(lint.ParsedArgs{
+			Stream:     args.Verbose,
+			CheckNames: args.LintChecks,
+			Autofix:    args.Autofix,
+			FromRef:    parentBranch,
+			Isolated:   args.IsolatedLint,
+			Skip:       args.LintSkip,
+			Output:     out,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Get all commits with their PR info
 	commits, err := pr.DetectAllPRs(repo, parentBranch)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Printf("Found %d commits in stack\n", len(commits))
+	fmt.Fprintf(out, "Found %d commits in stack\n", len(commits))
 
 	// Determine mode: any commit with a PR URL means update mode
 	hasAnyPR := false
@@ -94,20 +128,84 @@ func Stack(args StackParsedArgs) error {
 		}
 	}
 
+	var groups []stackGroup
 	if hasAnyPR {
 		// Update mode: group commits, absorbing orphans into their parent's PR
-		groups := groupCommits(commits, resolvedParent.GitHubBase)
-		return updateStack(repo, upstream, repoInfo, parentBranch, groups)
+		groups = groupCommits(commits, resolvedParent.GitHubBase)
+	} else {
+		// Create mode: one group per commit
+		for _, c := range commits {
+			groups = append(groups, stackGroup{
+				commits: []pr.StackCommitPR{c},
+			})
+		}
 	}
 
-	// Create mode: one group per commit
-	var groups []stackGroup
-	for _, c := range commits {
-		groups = append(groups, stackGroup{
-			commits: []pr.StackCommitPR{c},
-		})
+	// Find ticket references for each group, and make sure every PR that
+	// is about to be created has one if the repository requires it
+	ticketConfig, err := ticket.NewConfig(args.TicketTrackers, args.TicketTitlePrefix, args.TicketRequired)
+	if err != nil {
+		return nil, err
+	}
+	branchName := currentBranchName(repo)
+	for i, group := range groups {
+		var hashes []string
+		for _, c := range group.commits {
+			hashes = append(hashes, c.Hash)
+		}
+		groups[i].tickets = ticket.Detect(ticketConfig, branchName, commitMessages(repo, hashes))
+
+		if group.prNumber == 0 {
+			if err := ticket.Enforce(ticketConfig.Required, groups[i].tickets); err != nil {
+				return nil, fmt.Errorf("commit %s (%s): %w", group.commits[0].Hash[:8], group.commits[0].Summary, err)
+			}
+		}
+	}
+
+	if args.PerCommit && !args.NoVerify {
+		if args.Autofix {
+			fmt.Fprintln(out, "Skipping autofix: it is not supported with --per-commit")
+		}
+		fmt.Fprintln(out, "Running pre-commit checks for each PR...")
+		err = lint.LintRanges(
+			lint.ParsedArgs{Stream: args.Verbose, CheckNames: args.LintChecks, Skip: args.LintSkip, Output: out},
+			groupLintRanges(groups, parentBranch),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hasAnyPR {
+		return updateStack(repo, upstream, repoInfo, parentBranch, groups, args)
+	}
+	return createStack(repo, upstream, repoInfo, parentBranch, resolvedParent.GitHubBase, groups, args)
+}
+
+// groupLintRanges returns a lint range per group, checking the group's last
+// commit against the changes since the previous group
+func groupLintRanges(groups []stackGroup, parentBranch string) []lint.Range {
+	var ranges []lint.Range
+	from := parentBranch
+	for _, group := range groups {
+		last := group.commits[len(group.commits)-1]
+		name := fmt.Sprintf("commit %s (%s)", last.Hash[:8], last.Summary)
+		if group.prNumber > 0 {
+			name = fmt.Sprintf("PR #%d", group.prNumber)
+		}
+		ranges = append(ranges, lint.Range{Name: name, FromRef: from, ToRef: last.Hash})
+		from = last.Hash
+	}
+	return ranges
+}
+
+// groupPRContent returns the title and body for a new PR for the group
+func groupPRContent(group stackGroup, description string, args StackParsedArgs) (string, string) {
+	prTitle := group.commits[0].Summary
+	if args.TicketTitlePrefix {
+		prTitle = ticket.PrefixTitle(prTitle, group.tickets)
 	}
-	return createStack(repo, upstream, repoInfo, parentBranch, resolvedParent.GitHubBase, groups, args.OpenBrowser)
+	return prTitle, ticket.AddToBody(description, group.tickets)
 }
 
 // groupCommits organizes commits into groups based on PR ownership.
@@ -200,54 +298,97 @@ func updateStackDescriptions(owner, repo string, prs []stackPRInfo, prBodies map
 		body := upsertStackSection(prBodies[p.prNumber], section)
 		err := githubapi.UpdatePRBody(owner, repo, p.prNumber, body)
 		if err != nil {
-			fmt.Printf("Warning: failed to update description for PR #%d: %v\n", p.prNumber, err)
+			slog.Warn("failed to update PR description", "pr", p.prNumber, "err", err)
 		}
 	}
 }
 
+// groupHookPayload describes the PR of a group for hooks
+func groupHookPayload(repo *git.Repository, upstream string, group stackGroup) hooks.Payload {
+	payload := hooks.Payload{
+		PRNumber: group.prNumber,
+		PRURL:    group.prURL,
+		Remote:   upstream,
+		Branch:   group.branchName,
+		Base:     group.baseBranch,
+	}
+	payload.From, payload.To = commitRange(repo, group.commits[0].Hash+"^", group.commits[len(group.commits)-1].Hash)
+	return payload
+}
+
+// runPostStackHook runs the post-stack hook with every PR of the stack
+func runPostStackHook(repo *git.Repository, upstream, parentBranch string, groups []stackGroup, args StackParsedArgs) {
+	payload := hooks.Payload{Remote: upstream}
+	payload.From, payload.To = commitRange(repo, parentBranch, "HEAD")
+	for _, group := range groups {
+		payload.PRs = append(payload.PRs, groupHookPayload(repo, upstream, group))
+	}
+	if len(groups) > 0 {
+		payload.Base = groups[0].baseBranch
+	}
+	args.Hooks.RunPost(hooks.PostStack, payload, output(args.Output))
+}
+
 // createStack creates a new PR for each commit (mode 1: no existing PRs)
-func createStack(repo *git.Repository, upstream string, repoInfo *git.RepositoryInfo, parentBranch, defaultBase string, groups []stackGroup, openBrowser bool) error {
+func createStack(repo *git.Repository, upstream string, repoInfo *git.RepositoryInfo, parentBranch, defaultBase string, groups []stackGroup, args StackParsedArgs) (*Result, error) {
+	out := output(args.Output)
+
 	var createdPRs []*github.PullRequest
 	var prURLUpdates []commit.CommitPRURL
 	previousBase := defaultBase
 
-	initialContent := template.FindPRTemplate()
+	initialContent, err := loadPRTemplate(repo, args.Template)
+	if err != nil {
+		return nil, err
+	}
 
 	for i, group := range groups {
 		// Generate branch name
-		branchName, err := branch.GenerateUUIDBranchName()
+		branchName, err := branch.GenerateName(repo, upstream, args.BranchName, branch.NameData{
+			Prefix:  args.BranchPrefix,
+			Summary: group.commits[0].Summary,
+			Ticket:  ticket.BranchKey(group.tickets),
+		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		groups[i].branchName = branchName
 		groups[i].baseBranch = previousBase
 
+		// The pre-push hook may refuse the push, unless skipped by --no-verify
+		if !args.NoVerify {
+			if err := args.Hooks.Run(hooks.PrePush, groupHookPayload(repo, upstream, groups[i]), out); err != nil {
+				return nil, err
+			}
+		}
+
 		// Push cumulative commits up to this group's last commit
 		lastCommit := group.commits[len(group.commits)-1]
-		fmt.Printf("\nPushing commits up to %s to branch %s\n", lastCommit.Hash[:8], branchName)
+		fmt.Fprintf(out, "\nPushing commits up to %s to branch %s\n", lastCommit.Hash[:8], branchName)
 		_, err = repo.GitExec("push", "--force", upstream, fmt.Sprintf("%s:refs/heads/%s", lastCommit.Hash, branchName))
 		if err != nil {
-			return fmt.Errorf("error pushing to %s: %v", branchName, err)
+			return nil, fmt.Errorf("error pushing to %s: %v", branchName, err)
 		}
 
-		// PR title from the first commit in the group
-		prTitle := group.commits[0].Summary
-
 		// Get PR description from editor
-		fmt.Printf("\n--- PR #%d: %s ---\n", i+1, prTitle)
+		fmt.Fprintf(out, "\n--- PR #%d: %s ---\n", i+1, group.commits[0].Summary)
 		prDescription, err := editor.OpenEditor(initialContent)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		// PR title from the first commit in the group
+		prTitle, prDescription := groupPRContent(group, prDescription, args)
+
 		// Create PR
 		githubPR, err := githubapi.CreatePR(repoInfo.Owner, repoInfo.Name, prTitle, branchName, previousBase, prDescription, false, nil, nil)
 		if err != nil {
-			return fmt.Errorf("error creating PR for group %d: %v", i+1, err)
+			return nil, fmt.Errorf("error creating PR for group %d: %v", i+1, err)
 		}
 
 		createdPRs = append(createdPRs, githubPR)
-		fmt.Printf("Created PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
+		fmt.Fprintf(out, "Created PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
+		applyPRMetadata(repoInfo, *githubPR.Number, args.Assignees, args.Milestone, args.Project)
 
 		// Record that the first commit in each group should get the PR URL
 		prURLUpdates = append(prURLUpdates, commit.CommitPRURL{
@@ -260,17 +401,17 @@ func createStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 	}
 
 	// Stamp all PR URLs in a single rebase pass
-	fmt.Println("\nStamping PR URLs into commit messages...")
-	err := commit.UpdateMultipleCommitsWithPRURLs(repo, parentBranch, prURLUpdates)
+	fmt.Fprintln(out, "\nStamping PR URLs into commit messages...")
+	err = commit.UpdateMultipleCommitsWithPRURLs(out, repo, parentBranch, prURLUpdates)
 	if err != nil {
-		return fmt.Errorf("error stamping PR URLs: %v", err)
+		return nil, fmt.Errorf("error stamping PR URLs: %v", err)
 	}
 
 	// Re-push all branches with updated commit messages
-	fmt.Println("Re-pushing branches with updated commit messages...")
+	fmt.Fprintln(out, "Re-pushing branches with updated commit messages...")
 	commits, err := pr.DetectAllPRs(repo, parentBranch)
 	if err != nil {
-		return fmt.Errorf("error re-reading commits after stamping: %v", err)
+		return nil, fmt.Errorf("error re-reading commits after stamping: %v", err)
 	}
 
 	// Rebuild groups with updated hashes
@@ -283,12 +424,16 @@ func createStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 		branchName := groups[i].branchName
 		_, err = repo.GitExec("push", "--force", upstream, fmt.Sprintf("%s:refs/heads/%s", lastCommit.Hash, branchName))
 		if err != nil {
-			fmt.Printf("Warning: failed to re-push branch %s: %v\n", branchName, err)
+			slog.Warn("failed to re-push branch", "branch", branchName, "err", err)
 		}
+
+		updatedGroups[i].branchName = branchName
+		updatedGroups[i].baseBranch = groups[i].baseBranch
+		args.Hooks.RunPost(hooks.PostCreate, groupHookPayload(repo, upstream, updatedGroups[i]), out)
 	}
 
 	// Update all PR descriptions with the PR Stack section
-	fmt.Println("Updating PR descriptions with stack info...")
+	fmt.Fprintln(out, "Updating PR descriptions with stack info...")
 	var stackInfos []stackPRInfo
 	prBodies := make(map[int]string)
 	for i, githubPR := range createdPRs {
@@ -299,29 +444,40 @@ func createStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 		prBodies[*githubPR.Number] = githubPR.GetBody()
 	}
 	updateStackDescriptions(repoInfo.Owner, repoInfo.Name, stackInfos, prBodies)
+	runPostStackHook(repo, upstream, parentBranch, updatedGroups, args)
 
 	// Open browsers
-	if openBrowser {
+	if args.OpenBrowser {
 		for _, githubPR := range createdPRs {
 			_ = exec.Command("open", *githubPR.HTMLURL).Run()
 		}
 	}
 
 	// Print summary
-	fmt.Println("\n--- Stack Summary ---")
+	fmt.Fprintln(out, "\n--- Stack Summary ---")
 	for i, githubPR := range createdPRs {
-		fmt.Printf("  %d. PR #%d: %s\n", i+1, *githubPR.Number, *githubPR.HTMLURL)
+		fmt.Fprintf(out, "  %d. PR #%d: %s\n", i+1, *githubPR.Number, *githubPR.HTMLURL)
 	}
 
-	return nil
+	result := &Result{Remote: upstream, PullRequests: []PRResult{}}
+	for _, group := range updatedGroups {
+		result.PullRequests = append(result.PullRequests, groupResult(group, Created))
+	}
+	return result, nil
 }
 
 // updateStack updates existing PRs and absorbs orphan commits (mode 2)
-func updateStack(repo *git.Repository, upstream string, repoInfo *git.RepositoryInfo, parentBranch string, groups []stackGroup) error {
+func updateStack(repo *git.Repository, upstream string, repoInfo *git.RepositoryInfo, parentBranch string, groups []stackGroup, args StackParsedArgs) (*Result, error) {
+	out := output(args.Output)
+
 	var prURLUpdates []commit.CommitPRURL
 	var allPRURLs []string
+	created := make(map[int]bool) // PRs created by this run
 
-	initialContent := template.FindPRTemplate()
+	initialContent, err := loadPRTemplate(repo, args.Template)
+	if err != nil {
+		return nil, err
+	}
 
 	// First pass: resolve branch names for existing PRs, create new PRs for orphan groups
 	previousBase := ""
@@ -330,7 +486,7 @@ func updateStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 			// First group's base is the default branch
 			defaultBranch, err := repo.GetDefaultBranch()
 			if err != nil {
-				return err
+				return nil, err
 			}
 			previousBase = stripRemotePrefix(defaultBranch, upstream)
 		}
@@ -341,42 +497,53 @@ func updateStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 			// Existing PR - get its branch name
 			branchName, err := githubapi.GetRemoteBranchFromPR(repoInfo.Owner, repoInfo.Name, group.prNumber)
 			if err != nil {
-				return fmt.Errorf("error getting branch for PR #%d: %v", group.prNumber, err)
+				return nil, fmt.Errorf("error getting branch for PR #%d: %v", group.prNumber, err)
 			}
 			groups[i].branchName = branchName
 			previousBase = branchName
 		} else {
 			// Orphan group - create a new PR
-			branchName, err := branch.GenerateUUIDBranchName()
+			branchName, err := branch.GenerateName(repo, upstream, args.BranchName, branch.NameData{
+				Prefix:  args.BranchPrefix,
+				Summary: group.commits[0].Summary,
+				Ticket:  ticket.BranchKey(group.tickets),
+			})
 			if err != nil {
-				return err
+				return nil, err
 			}
 			groups[i].branchName = branchName
 
+			// The pre-push hook may refuse the push, unless skipped by --no-verify
+			if !args.NoVerify {
+				if err := args.Hooks.Run(hooks.PrePush, groupHookPayload(repo, upstream, groups[i]), out); err != nil {
+					return nil, err
+				}
+			}
+
 			// Push commits up to this group
 			lastCommit := group.commits[len(group.commits)-1]
-			fmt.Printf("\nPushing new commits to branch %s\n", branchName)
+			fmt.Fprintf(out, "\nPushing new commits to branch %s\n", branchName)
 			_, err = repo.GitExec("push", "--force", upstream, fmt.Sprintf("%s:refs/heads/%s", lastCommit.Hash, branchName))
 			if err != nil {
-				return fmt.Errorf("error pushing to %s: %v", branchName, err)
+				return nil, fmt.Errorf("error pushing to %s: %v", branchName, err)
 			}
 
-			prTitle := group.commits[0].Summary
-
-			fmt.Printf("\n--- New PR: %s ---\n", prTitle)
+			fmt.Fprintf(out, "\n--- New PR: %s ---\n", group.commits[0].Summary)
 			prDescription, err := editor.OpenEditor(initialContent)
 			if err != nil {
-				return err
+				return nil, err
 			}
+			prTitle, prDescription := groupPRContent(group, prDescription, args)
 
 			githubPR, err := githubapi.CreatePR(repoInfo.Owner, repoInfo.Name, prTitle, branchName, previousBase, prDescription, false, nil, nil)
 			if err != nil {
-				return fmt.Errorf("error creating PR: %v", err)
+				return nil, fmt.Errorf("error creating PR: %v", err)
 			}
 
 			groups[i].prNumber = *githubPR.Number
 			groups[i].prURL = *githubPR.HTMLURL
-			fmt.Printf("Created PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
+			created[*githubPR.Number] = true
+			fmt.Fprintf(out, "Created PR #%d: %s\n", *githubPR.Number, *githubPR.HTMLURL)
 
 			prURLUpdates = append(prURLUpdates, commit.CommitPRURL{
 				Hash:  group.commits[0].Hash,
@@ -389,16 +556,16 @@ func updateStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 
 	// Stamp any new PR URLs
 	if len(prURLUpdates) > 0 {
-		fmt.Println("\nStamping PR URLs into commit messages...")
-		err := commit.UpdateMultipleCommitsWithPRURLs(repo, parentBranch, prURLUpdates)
+		fmt.Fprintln(out, "\nStamping PR URLs into commit messages...")
+		err := commit.UpdateMultipleCommitsWithPRURLs(out, repo, parentBranch, prURLUpdates)
 		if err != nil {
-			return fmt.Errorf("error stamping PR URLs: %v", err)
+			return nil, fmt.Errorf("error stamping PR URLs: %v", err)
 		}
 
 		// Re-read commits after rebase changed hashes
 		commits, err := pr.DetectAllPRs(repo, parentBranch)
 		if err != nil {
-			return fmt.Errorf("error re-reading commits: %v", err)
+			return nil, fmt.Errorf("error re-reading commits: %v", err)
 		}
 		// Rebuild groups to get updated hashes
 		defaultBranch, _ := repo.GetDefaultBranch()
@@ -418,19 +585,44 @@ func updateStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 	// Second pass: push all branches, update PR bases, and collect PR info
 	var stackInfos []stackPRInfo
 	prBodies := make(map[int]string)
+	result := &Result{Remote: upstream, PullRequests: []PRResult{}}
 
 	for i, group := range groups {
+		// New PRs already ran the pre-push hook before their first push
+		if !args.NoVerify && !created[group.prNumber] {
+			if err := args.Hooks.Run(hooks.PrePush, groupHookPayload(repo, upstream, group), out); err != nil {
+				return nil, err
+			}
+		}
+
 		lastCommit := group.commits[len(group.commits)-1]
-		fmt.Printf("Pushing to %s (PR #%d)\n", group.branchName, group.prNumber)
-		_, err := repo.GitExec("push", "--force", upstream, fmt.Sprintf("%s:refs/heads/%s", lastCommit.Hash, group.branchName))
+		fmt.Fprintf(out, "Pushing to %s (PR #%d)\n", group.branchName, group.prNumber)
+		pushed, err := forcePush(repo, upstream, fmt.Sprintf("%s:refs/heads/%s", lastCommit.Hash, group.branchName))
 		if err != nil {
-			return fmt.Errorf("error pushing to %s: %v", group.branchName, err)
+			return nil, fmt.Errorf("error pushing to %s: %v", group.branchName, err)
+		}
+
+		action := Unchanged
+		switch {
+		case created[group.prNumber]:
+			action = Created
+		case pushed:
+			action = Updated
+		}
+
+		if created[group.prNumber] {
+			args.Hooks.RunPost(hooks.PostCreate, groupHookPayload(repo, upstream, group), out)
+		} else {
+			args.Hooks.RunPost(hooks.PostUpdate, groupHookPayload(repo, upstream, group), out)
 		}
 
+		// Keep assignees, milestone and project in sync with the current settings
+		applyPRMetadata(repoInfo, group.prNumber, args.Assignees, args.Milestone, args.Project)
+
 		// Update the PR base branch
 		err = githubapi.UpdatePRBase(repoInfo.Owner, repoInfo.Name, group.prNumber, group.baseBranch)
 		if err != nil {
-			fmt.Printf("Warning: failed to update base for PR #%d: %v\n", group.prNumber, err)
+			slog.Warn("failed to update PR base", "pr", group.prNumber, "base", group.baseBranch, "err", err)
 		}
 
 		// Collect PR info for stack description and summary
@@ -443,21 +635,25 @@ func updateStack(repo *git.Repository, upstream string, repoInfo *git.Repository
 		githubPR, err := githubapi.GetExistingPR(repoInfo.Owner, repoInfo.Name, group.prNumber)
 		if err == nil {
 			prBodies[group.prNumber] = githubPR.GetBody()
+			groups[i].prURL = *githubPR.HTMLURL
 			allPRURLs = append(allPRURLs, fmt.Sprintf("  %d. PR #%d: %s", i+1, group.prNumber, *githubPR.HTMLURL))
 		} else if group.prURL != "" {
 			allPRURLs = append(allPRURLs, fmt.Sprintf("  %d. PR #%d: %s", i+1, group.prNumber, group.prURL))
 		}
+
+		result.PullRequests = append(result.PullRequests, groupResult(groups[i], action))
 	}
 
 	// Update all PR descriptions with the PR Stack section
-	fmt.Println("Updating PR descriptions with stack info...")
+	fmt.Fprintln(out, "Updating PR descriptions with stack info...")
 	updateStackDescriptions(repoInfo.Owner, repoInfo.Name, stackInfos, prBodies)
+	runPostStackHook(repo, upstream, parentBranch, groups, args)
 
 	// Print summary
-	fmt.Println("\n--- Stack Summary ---")
+	fmt.Fprintln(out, "\n--- Stack Summary ---")
 	for _, line := range allPRURLs {
-		fmt.Println(line)
+		fmt.Fprintln(out, line)
 	}
 
-	return nil
+	return result, nil
 }
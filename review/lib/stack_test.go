@@ -1,9 +1,11 @@
 package review
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	lint "github.com/jtamagnan/git-utils/lint/lib"
 	"github.com/jtamagnan/git-utils/review/lib/pr"
 )
 
@@ -163,6 +165,23 @@ func TestGroupCommits_SentinelWithFollowingOrphan(t *testing.T) {
 	}
 }
 
+func TestGroupLintRanges(t *testing.T) {
+	groups := []stackGroup{
+		{commits: []pr.StackCommitPR{{Hash: "aaaaaaaaaa", Summary: "First"}, {Hash: "bbbbbbbbbb", Summary: "Second"}}, prNumber: 4},
+		{commits: []pr.StackCommitPR{{Hash: "cccccccccc", Summary: "Third"}}},
+	}
+
+	ranges := groupLintRanges(groups, "origin/main")
+
+	expected := []lint.Range{
+		{Name: "PR #4", FromRef: "origin/main", ToRef: "bbbbbbbbbb"},
+		{Name: "commit cccccccc (Third)", FromRef: "bbbbbbbbbb", ToRef: "cccccccccc"},
+	}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("Expected %v, got %v", expected, ranges)
+	}
+}
+
 func TestBuildStackSection(t *testing.T) {
 	prs := []stackPRInfo{
 		{title: "Add auth module", prNumber: 10},
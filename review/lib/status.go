@@ -0,0 +1,70 @@
+package review
+
+import (
+	"fmt"
+
+	"github.com/jtamagnan/git-utils/git"
+	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+	"github.com/jtamagnan/git-utils/review/lib/parent"
+	"github.com/jtamagnan/git-utils/review/lib/pr"
+)
+
+// StatusParsedArgs represents the parsed command line arguments for the status command
+type StatusParsedArgs struct {
+	Parent string
+}
+
+// Status describes the PRs of the commits between the parent branch and HEAD,
+// in stack order. Commits without a PR are reported with PR number 0.
+func Status(args StatusParsedArgs) (*Result, error) {
+	repo, err := git.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	upstream, err := repo.Remote()
+	if err != nil {
+		return nil, fmt.Errorf("no upstream branch configured for current branch - run 'git branch --set-upstream-to=<remote>/<branch>' to set upstream")
+	}
+
+	upstreamURL, err := repo.GetRemoteURL(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	repoInfo, err := git.ParseRepositoryInfo(upstreamURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedParent, err := parent.ResolveParent(repo, args.Parent, repoInfo.Owner, repoInfo.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := pr.DetectAllPRs(repo, resolvedParent.GitRef)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Remote: upstream, PullRequests: []PRResult{}}
+	for _, group := range groupCommits(commits, resolvedParent.GitHubBase) {
+		prResult := groupResult(group, "")
+		if group.prNumber > 0 {
+			githubPR, err := githubapi.GetExistingPR(repoInfo.Owner, repoInfo.Name, group.prNumber)
+			if err != nil {
+				return nil, err
+			}
+			prResult.URL = githubPR.GetHTMLURL()
+			prResult.Head = githubPR.GetHead().GetRef()
+			prResult.Base = githubPR.GetBase().GetRef()
+			prResult.State = githubPR.GetState()
+			if githubPR.GetMerged() {
+				prResult.State = "merged"
+			}
+		}
+		result.PullRequests = append(result.PullRequests, prResult)
+	}
+
+	return result, nil
+}
@@ -2,12 +2,27 @@ package template
 
 import (
 	"embed"
+	"fmt"
 	"os"
 )
 
 //go:embed default_pull_request_template.md
 var defaultTemplate embed.FS
 
+// LoadPRTemplate reads the PR template at path, or looks for one in the
+// standard locations if path is empty
+func LoadPRTemplate(path string) (string, error) {
+	if path == "" {
+		return FindPRTemplate(), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PR template %s: %v", path, err)
+	}
+	return string(content), nil
+}
+
 // FindPRTemplate looks for GitHub PR templates in standard locations
 func FindPRTemplate() string {
 	templatePaths := []string{
@@ -0,0 +1,266 @@
+package threads
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jtamagnan/git-utils/git"
+	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+)
+
+// Hunk is a single "@@ -a,b +c,d @@" section of a unified diff
+type Hunk struct {
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+}
+
+// FileDiff describes how one file changed between two commits
+type FileDiff struct {
+	OldPath string
+	NewPath string // empty if the file was deleted
+	Hunks   []Hunk
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses the output of `git diff -U0 -M` into per-file diffs keyed by
+// the path of the file in the old commit. Files added in the new commit are
+// not included since nothing in the old commit can point at them.
+func ParseDiff(diff string) map[string]*FileDiff {
+	files := make(map[string]*FileDiff)
+
+	var current *FileDiff
+	flush := func() {
+		if current != nil && current.OldPath != "" {
+			files[current.OldPath] = current
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &FileDiff{}
+			// Fallback for diffs without ---/+++ lines (e.g. mode changes)
+			if parts := strings.SplitN(strings.TrimPrefix(line, "diff --git a/"), " b/", 2); len(parts) == 2 {
+				current.OldPath = parts[0]
+				current.NewPath = parts[1]
+			}
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "rename from "):
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.NewPath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "new file mode"):
+			current.OldPath = ""
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.NewPath = ""
+		case strings.HasPrefix(line, "--- "):
+			if path := strings.TrimPrefix(line, "--- "); path == "/dev/null" {
+				current.OldPath = ""
+			} else {
+				current.OldPath = strings.TrimPrefix(path, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path == "/dev/null" {
+				current.NewPath = ""
+			} else {
+				current.NewPath = strings.TrimPrefix(path, "b/")
+			}
+		default:
+			matches := hunkHeaderRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			current.Hunks = append(current.Hunks, Hunk{
+				OldStart: atoiDefault(matches[1], 1),
+				OldCount: atoiDefault(matches[2], 1),
+				NewStart: atoiDefault(matches[3], 1),
+				NewCount: atoiDefault(matches[4], 1),
+			})
+		}
+	}
+	flush()
+
+	return files
+}
+
+// atoiDefault converts s to an int, returning def if s is empty or invalid
+func atoiDefault(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return def
+}
+
+// MapLine translates a line number in the old version of the file to the
+// corresponding line in the new version. The boolean result is false when the
+// line itself was modified or removed, in which case the returned line is the
+// closest surviving position.
+func (fd *FileDiff) MapLine(line int) (int, bool) {
+	offset := 0
+	for _, h := range fd.Hunks {
+		if h.OldCount == 0 {
+			// Pure insertion after line OldStart
+			if line <= h.OldStart {
+				break
+			}
+			offset += h.NewCount
+			continue
+		}
+
+		if line < h.OldStart {
+			break
+		}
+		if line < h.OldStart+h.OldCount {
+			// The line was changed or deleted in this hunk
+			mapped := h.NewStart
+			if h.NewCount == 0 {
+				// Deleted lines: point at the line that now follows the removal
+				mapped = h.NewStart + 1
+			}
+			return max(mapped, 1), false
+		}
+		offset += h.NewCount - h.OldCount
+	}
+	return line + offset, true
+}
+
+// Position is a location in the files at HEAD
+type Position struct {
+	Path  string
+	Line  int
+	Exact bool // false if the original line was modified, removed, or could not be tracked
+	Gone  bool // true if the file no longer exists at HEAD
+}
+
+// LineMapper maps positions in older commits to positions at HEAD, caching
+// one diff per commit
+type LineMapper struct {
+	repo  *git.Repository
+	diffs map[string]map[string]*FileDiff
+}
+
+// NewLineMapper creates a LineMapper for the given repository
+func NewLineMapper(repo *git.Repository) *LineMapper {
+	return &LineMapper{
+		repo:  repo,
+		diffs: make(map[string]map[string]*FileDiff),
+	}
+}
+
+// Map translates path:line as of commit into the corresponding position at HEAD.
+// An error is returned if the commit is not available locally.
+func (m *LineMapper) Map(commit, path string, line int) (Position, error) {
+	files, ok := m.diffs[commit]
+	if !ok {
+		if _, err := m.repo.GitExec("cat-file", "-e", commit+"^{commit}"); err != nil {
+			return Position{}, fmt.Errorf("commit %s is not available locally", commit)
+		}
+		out, err := m.repo.GitExec("diff", "--no-color", "--no-ext-diff", "-U0", "-M", commit, "HEAD")
+		if err != nil {
+			return Position{}, err
+		}
+		files = ParseDiff(out)
+		m.diffs[commit] = files
+	}
+
+	fd, changed := files[path]
+	if !changed {
+		return Position{Path: path, Line: line, Exact: true}, nil
+	}
+	if fd.NewPath == "" {
+		return Position{Path: path, Line: line, Gone: true}, nil
+	}
+
+	mapped, exact := fd.MapLine(line)
+	return Position{Path: fd.NewPath, Line: mapped, Exact: exact}, nil
+}
+
+// Entry is a review thread placed at its current location in the local checkout
+type Entry struct {
+	PRNumber int
+	Position Position
+	Thread   githubapi.ReviewThread
+}
+
+// note returns a short annotation describing the state of the entry
+func (e Entry) note() string {
+	var notes []string
+	if e.Thread.IsResolved {
+		notes = append(notes, "resolved")
+	}
+	if e.Thread.IsOutdated {
+		notes = append(notes, "outdated")
+	}
+	if e.Position.Gone {
+		notes = append(notes, "file deleted locally")
+	} else if !e.Position.Exact {
+		notes = append(notes, "line changed locally")
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+// SortEntries orders entries by path, then line, then PR number
+func SortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Position, entries[j].Position
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return entries[i].PRNumber < entries[j].PRNumber
+	})
+}
+
+// WriteGrouped prints entries grouped by file for reading in the terminal.
+// Entries must already be sorted with SortEntries.
+func WriteGrouped(w io.Writer, entries []Entry) {
+	currentPath := ""
+	for i, e := range entries {
+		if i == 0 || e.Position.Path != currentPath {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+			currentPath = e.Position.Path
+			_, _ = fmt.Fprintf(w, "%s\n", currentPath)
+		}
+
+		_, _ = fmt.Fprintf(w, "  line %d, PR #%d%s\n", e.Position.Line, e.PRNumber, e.note())
+		for _, c := range e.Thread.Comments {
+			_, _ = fmt.Fprintf(w, "    %s:\n", c.Author)
+			for _, bodyLine := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+				_, _ = fmt.Fprintf(w, "      %s\n", strings.TrimRight(bodyLine, "\r"))
+			}
+		}
+		if len(e.Thread.Comments) > 0 {
+			_, _ = fmt.Fprintf(w, "    %s\n", e.Thread.Comments[0].URL)
+		}
+	}
+}
+
+// WriteQuickfix prints one "file:line: message" line per comment, suitable for
+// vim's quickfix list (`vim -q`) or emacs compilation-mode. Paths are made
+// relative with relPath.
+func WriteQuickfix(w io.Writer, entries []Entry, relPath func(string) string) {
+	for _, e := range entries {
+		for _, c := range e.Thread.Comments {
+			message := strings.Join(strings.Fields(c.Body), " ")
+			_, _ = fmt.Fprintf(w, "%s:%d: [PR #%d] %s: %s%s\n",
+				relPath(e.Position.Path), e.Position.Line, e.PRNumber, c.Author, message, e.note())
+		}
+	}
+}
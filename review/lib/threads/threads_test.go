@@ -0,0 +1,194 @@
+package threads
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jtamagnan/git-utils/git"
+	githubapi "github.com/jtamagnan/git-utils/review/lib/github"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ package main
++import "fmt"
++
+@@ -10 +12 @@ func main() {
+-	println("hi")
++	fmt.Println("hi")
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-a
+-b
+diff --git a/added.txt b/added.txt
+new file mode 100644
+index 0000000..4444444
+--- /dev/null
++++ b/added.txt
+@@ -0,0 +1 @@
++new
+`
+
+	files := ParseDiff(diff)
+
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 files (added files are skipped), got %d: %v", len(files), files)
+	}
+
+	mainDiff := files["main.go"]
+	if mainDiff == nil {
+		t.Fatal("Expected diff for main.go")
+	}
+	expectedHunks := []Hunk{
+		{OldStart: 3, OldCount: 0, NewStart: 4, NewCount: 2},
+		{OldStart: 10, OldCount: 1, NewStart: 12, NewCount: 1},
+	}
+	if len(mainDiff.Hunks) != len(expectedHunks) {
+		t.Fatalf("Expected %d hunks, got %d", len(expectedHunks), len(mainDiff.Hunks))
+	}
+	for i, h := range expectedHunks {
+		if mainDiff.Hunks[i] != h {
+			t.Errorf("Hunk %d: expected %+v, got %+v", i, h, mainDiff.Hunks[i])
+		}
+	}
+
+	if renamed := files["old.txt"]; renamed == nil || renamed.NewPath != "new.txt" {
+		t.Errorf("Expected old.txt to be renamed to new.txt, got %+v", renamed)
+	}
+
+	if deleted := files["gone.txt"]; deleted == nil || deleted.NewPath != "" {
+		t.Errorf("Expected gone.txt to be marked as deleted, got %+v", deleted)
+	}
+}
+
+func TestMapLine(t *testing.T) {
+	fd := &FileDiff{
+		OldPath: "file.txt",
+		NewPath: "file.txt",
+		Hunks: []Hunk{
+			{OldStart: 3, OldCount: 0, NewStart: 4, NewCount: 2},   // 2 lines inserted after line 3
+			{OldStart: 10, OldCount: 1, NewStart: 12, NewCount: 1}, // line 10 modified
+			{OldStart: 20, OldCount: 2, NewStart: 21, NewCount: 0}, // lines 20-21 deleted
+		},
+	}
+
+	tests := []struct {
+		name          string
+		line          int
+		expectedLine  int
+		expectedExact bool
+	}{
+		{"before any change", 1, 1, true},
+		{"at insertion point", 3, 3, true},
+		{"after insertion", 4, 6, true},
+		{"modified line", 10, 12, false},
+		{"after modification", 11, 13, true},
+		{"deleted line", 20, 22, false},
+		{"after deletion", 22, 22, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, exact := fd.MapLine(tt.line)
+			if line != tt.expectedLine || exact != tt.expectedExact {
+				t.Errorf("MapLine(%d) = (%d, %v), expected (%d, %v)", tt.line, line, exact, tt.expectedLine, tt.expectedExact)
+			}
+		})
+	}
+}
+
+func TestLineMapperFollowsLocalCommits(t *testing.T) {
+	testRepo := git.NewTestRepo(t)
+	defer testRepo.Cleanup()
+
+	testRepo.InDir(func() {
+		testRepo.AddCommit("file.txt", "one\ntwo\nthree\nfour\n", "Initial commit")
+		reviewed := testRepo.GitExec("rev-parse", "HEAD")
+
+		// Local follow-up commits: insert lines at the top, rename the file
+		testRepo.AddCommit("file.txt", "zero\nhalf\none\ntwo\nthree\nfour\n", "Insert lines")
+		testRepo.GitExec("mv", "file.txt", "renamed.txt")
+		testRepo.GitExec("commit", "-m", "Rename file")
+		testRepo.RefreshRepo()
+
+		mapper := NewLineMapper(testRepo.Repo)
+
+		position, err := mapper.Map(reviewed, "file.txt", 3)
+		if err != nil {
+			t.Fatalf("Map failed: %v", err)
+		}
+		if position.Path != "renamed.txt" || position.Line != 5 || !position.Exact {
+			t.Errorf("Expected renamed.txt:5 (exact), got %+v", position)
+		}
+
+		// Unknown commits are reported as errors so callers can fall back
+		_, err = mapper.Map(strings.Repeat("0", 40), "file.txt", 3)
+		if err == nil {
+			t.Error("Expected error for a commit that does not exist locally")
+		}
+	})
+}
+
+func TestWriteQuickfix(t *testing.T) {
+	entries := []Entry{
+		{
+			PRNumber: 12,
+			Position: Position{Path: "b.go", Line: 7, Exact: true},
+			Thread: githubapi.ReviewThread{
+				Comments: []githubapi.ReviewComment{{Author: "bob", Body: "Second\nfile"}},
+			},
+		},
+		{
+			PRNumber: 12,
+			Position: Position{Path: "a.go", Line: 3},
+			Thread: githubapi.ReviewThread{
+				IsResolved: true,
+				Comments: []githubapi.ReviewComment{
+					{Author: "alice", Body: "Please rename this"},
+					{Author: "me", Body: "Done"},
+				},
+			},
+		},
+	}
+	SortEntries(entries)
+
+	var out bytes.Buffer
+	WriteQuickfix(&out, entries, func(path string) string { return "src/" + path })
+
+	expected := "src/a.go:3: [PR #12] alice: Please rename this (resolved, line changed locally)\n" +
+		"src/a.go:3: [PR #12] me: Done (resolved, line changed locally)\n" +
+		"src/b.go:7: [PR #12] bob: Second file\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected quickfix output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestWriteGroupedGroupsByFile(t *testing.T) {
+	entries := []Entry{
+		{PRNumber: 1, Position: Position{Path: "a.go", Line: 1, Exact: true}, Thread: githubapi.ReviewThread{Comments: []githubapi.ReviewComment{{Author: "alice", Body: "one"}}}},
+		{PRNumber: 1, Position: Position{Path: "a.go", Line: 9, Exact: true}, Thread: githubapi.ReviewThread{Comments: []githubapi.ReviewComment{{Author: "alice", Body: "two"}}}},
+		{PRNumber: 2, Position: Position{Path: "b.go", Line: 4, Exact: true}, Thread: githubapi.ReviewThread{Comments: []githubapi.ReviewComment{{Author: "bob", Body: "three"}}}},
+	}
+
+	var out bytes.Buffer
+	WriteGrouped(&out, entries)
+
+	if strings.Count(out.String(), "a.go\n") != 1 {
+		t.Errorf("Expected a.go heading exactly once, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "line 4, PR #2") {
+		t.Errorf("Expected PR #2 thread in output, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,222 @@
+package ticket
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ticket is an issue-tracker reference found in a branch name or commit message
+type Ticket struct {
+	Key string // e.g. "ENG-123", "#456", or a full URL from a trailer
+	URL string // empty if no tracker is configured for the key
+}
+
+// Config controls which ticket keys are recognized and how they are linked
+type Config struct {
+	// Trackers maps a key prefix (e.g. "ENG", or "#" for numeric issue
+	// references) to a URL template. "{key}" in the template is replaced by
+	// the full key (e.g. "ENG-123") and "{id}" by its number (e.g. "123").
+	Trackers    map[string]string
+	TitlePrefix bool
+	Required    string // "", "off", "warn" or "error"
+}
+
+// NewConfig builds a Config from "PREFIX=URL-template" tracker entries
+func NewConfig(trackers []string, titlePrefix bool, required string) (Config, error) {
+	cfg := Config{
+		Trackers:    make(map[string]string),
+		TitlePrefix: titlePrefix,
+		Required:    required,
+	}
+
+	for _, entry := range trackers {
+		prefix, urlTemplate, ok := strings.Cut(entry, "=")
+		prefix = strings.TrimSpace(prefix)
+		urlTemplate = strings.TrimSpace(urlTemplate)
+		if !ok || prefix == "" || urlTemplate == "" {
+			return cfg, fmt.Errorf("invalid ticket tracker %q: expected PREFIX=URL (e.g. ENG=https://linear.app/acme/issue/{key})", entry)
+		}
+		if prefix != "#" {
+			prefix = strings.ToUpper(prefix)
+		}
+		cfg.Trackers[prefix] = urlTemplate
+	}
+
+	switch required {
+	case "", "off", "warn", "error":
+	default:
+		return cfg, fmt.Errorf("invalid ticket-required value %q: expected off, warn or error", required)
+	}
+
+	return cfg, nil
+}
+
+// anyKeyRegex matches any tracker-style key; only used for explicit trailers
+var anyKeyRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)-(\d+)$`)
+
+// issueRefRegex matches "#123" references that are not part of a word or URL
+var issueRefRegex = regexp.MustCompile(`(?:^|[\s(\[])#(\d+)\b`)
+
+// trailerRegex matches trailers that explicitly reference tickets
+var trailerRegex = regexp.MustCompile(`(?mi)^(?:fixes|closes|resolves|refs|ticket|issue):[ \t]*(\S.*)$`)
+
+// prefixedKeyRegex builds a case-insensitive regex matching keys for the
+// configured tracker prefixes, or nil if none are configured
+func (cfg Config) prefixedKeyRegex() *regexp.Regexp {
+	var prefixes []string
+	for prefix := range cfg.Trackers {
+		if prefix != "#" {
+			prefixes = append(prefixes, regexp.QuoteMeta(prefix))
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+	sort.Strings(prefixes)
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(prefixes, "|") + `)-(\d+)\b`)
+}
+
+// newTicket builds a ticket for prefix-number, linking it if a tracker is configured
+func (cfg Config) newTicket(prefix, number string) Ticket {
+	key := strings.ToUpper(prefix) + "-" + number
+	if prefix == "#" {
+		key = "#" + number
+	}
+
+	t := Ticket{Key: key}
+	trackerPrefix := prefix
+	if prefix != "#" {
+		trackerPrefix = strings.ToUpper(prefix)
+	}
+	if urlTemplate, ok := cfg.Trackers[trackerPrefix]; ok {
+		t.URL = strings.NewReplacer("{key}", key, "{id}", number).Replace(urlTemplate)
+	}
+	return t
+}
+
+// Detect finds ticket references in the branch name and commit messages.
+// Tracker keys (e.g. "ENG-123") are only recognized for configured prefixes
+// so that strings like "UTF-8" are not mistaken for tickets; "#123" references
+// and Fixes:/Closes:/Refs: trailers are always recognized. Results are
+// de-duplicated and ordered by first appearance.
+func Detect(cfg Config, branchName string, messages []string) []Ticket {
+	var tickets []Ticket
+	seen := make(map[string]bool)
+	add := func(t Ticket) {
+		if !seen[t.Key] {
+			seen[t.Key] = true
+			tickets = append(tickets, t)
+		}
+	}
+
+	prefixedKeys := cfg.prefixedKeyRegex()
+	if prefixedKeys != nil {
+		for _, m := range prefixedKeys.FindAllStringSubmatch(branchName, -1) {
+			add(cfg.newTicket(m[1], m[2]))
+		}
+	}
+
+	for _, message := range messages {
+		for _, m := range trailerRegex.FindAllStringSubmatch(message, -1) {
+			for _, ref := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' }) {
+				if t, ok := cfg.parseTrailerRef(ref); ok {
+					add(t)
+				}
+			}
+		}
+		if prefixedKeys != nil {
+			for _, m := range prefixedKeys.FindAllStringSubmatch(message, -1) {
+				add(cfg.newTicket(m[1], m[2]))
+			}
+		}
+		for _, m := range issueRefRegex.FindAllStringSubmatch(message, -1) {
+			add(cfg.newTicket("#", m[1]))
+		}
+	}
+
+	return tickets
+}
+
+// parseTrailerRef parses a single reference from a Fixes:-style trailer
+func (cfg Config) parseTrailerRef(ref string) (Ticket, bool) {
+	switch {
+	case strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://"):
+		return Ticket{Key: ref, URL: ref}, true
+	case strings.HasPrefix(ref, "#") && len(ref) > 1:
+		return cfg.newTicket("#", ref[1:]), true
+	}
+	if m := anyKeyRegex.FindStringSubmatch(ref); m != nil {
+		return cfg.newTicket(m[1], m[2]), true
+	}
+	return Ticket{}, false
+}
+
+// BranchKey returns the key of the first ticket that can be used in a branch
+// name, or "" if there is none. URL-only references are skipped.
+func BranchKey(tickets []Ticket) string {
+	for _, t := range tickets {
+		if t.Key != t.URL {
+			return t.Key
+		}
+	}
+	return ""
+}
+
+// Markdown renders the ticket as a markdown link if it has a URL
+func (t Ticket) Markdown() string {
+	if t.URL == "" || t.URL == t.Key {
+		return t.Key
+	}
+	return fmt.Sprintf("[%s](%s)", t.Key, t.URL)
+}
+
+// AddToBody appends a "## Tickets" section linking the tickets to a PR body.
+// The body is returned unchanged if it has no tickets or already has the section.
+func AddToBody(body string, tickets []Ticket) string {
+	if len(tickets) == 0 || strings.Contains(body, "## Tickets") {
+		return body
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(body, " \t\r\n"))
+	b.WriteString("\n\n## Tickets\n\n")
+	for _, t := range tickets {
+		b.WriteString("- " + t.Markdown() + "\n")
+	}
+	return b.String()
+}
+
+// PrefixTitle prefixes a PR title with the ticket keys that are not already
+// mentioned in it, e.g. "[ENG-123] Fix login"
+func PrefixTitle(title string, tickets []Ticket) string {
+	var keys []string
+	for _, t := range tickets {
+		if t.Key == t.URL || strings.Contains(title, t.Key) {
+			continue // URL-only references make poor titles
+		}
+		keys = append(keys, t.Key)
+	}
+	if len(keys) == 0 {
+		return title
+	}
+	return fmt.Sprintf("[%s] %s", strings.Join(keys, ", "), title)
+}
+
+// Enforce checks the ticket requirement. With "warn" it prints a warning when
+// no ticket was found; with "error" it returns an error.
+func Enforce(required string, tickets []Ticket) error {
+	if len(tickets) > 0 {
+		return nil
+	}
+
+	switch required {
+	case "warn":
+		slog.Warn("no ticket found in the branch name or commit messages")
+	case "error":
+		return fmt.Errorf("this repository requires a ticket: reference one in the branch name, a commit message, or a 'Fixes:' trailer")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package ticket
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustConfig(t *testing.T, trackers ...string) Config {
+	t.Helper()
+	cfg, err := NewConfig(trackers, false, "")
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+	return cfg
+}
+
+func TestNewConfigRejectsInvalidValues(t *testing.T) {
+	if _, err := NewConfig([]string{"ENG"}, false, ""); err == nil {
+		t.Error("Expected error for tracker without URL")
+	}
+	if _, err := NewConfig(nil, false, "sometimes"); err == nil {
+		t.Error("Expected error for invalid ticket-required value")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	cfg := mustConfig(t,
+		"ENG=https://linear.app/acme/issue/{key}",
+		"#=https://github.com/acme/app/issues/{id}",
+	)
+
+	tests := []struct {
+		name       string
+		branchName string
+		messages   []string
+		expected   []Ticket
+	}{
+		{
+			name:       "key in branch name",
+			branchName: "jat/eng-123-fix-login",
+			expected:   []Ticket{{Key: "ENG-123", URL: "https://linear.app/acme/issue/ENG-123"}},
+		},
+		{
+			name:     "issue reference in commit message",
+			messages: []string{"Fix login\n\nSee #456 for details"},
+			expected: []Ticket{{Key: "#456", URL: "https://github.com/acme/app/issues/456"}},
+		},
+		{
+			name:     "fixes trailer with URL and unconfigured key",
+			messages: []string{"Fix login\n\nFixes: https://tracker.example.com/T-1, OPS-9"},
+			expected: []Ticket{
+				{Key: "https://tracker.example.com/T-1", URL: "https://tracker.example.com/T-1"},
+				{Key: "OPS-9"},
+			},
+		},
+		{
+			name:     "unconfigured prefixes are ignored outside trailers",
+			messages: []string{"Switch to UTF-8 and SHA-256"},
+			expected: nil,
+		},
+		{
+			name:       "duplicates are removed",
+			branchName: "ENG-123",
+			messages:   []string{"ENG-123: fix", "Refs: ENG-123"},
+			expected:   []Ticket{{Key: "ENG-123", URL: "https://linear.app/acme/issue/ENG-123"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tickets := Detect(cfg, tt.branchName, tt.messages)
+			if !reflect.DeepEqual(tickets, tt.expected) {
+				t.Errorf("Detect() = %+v, expected %+v", tickets, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddToBody(t *testing.T) {
+	tickets := []Ticket{
+		{Key: "ENG-123", URL: "https://linear.app/acme/issue/ENG-123"},
+		{Key: "#456"},
+	}
+
+	body := AddToBody("## Summary\n\nFix login\n", tickets)
+	expected := "## Summary\n\nFix login\n\n## Tickets\n\n- [ENG-123](https://linear.app/acme/issue/ENG-123)\n- #456\n"
+	if body != expected {
+		t.Errorf("AddToBody() = %q, expected %q", body, expected)
+	}
+
+	// Adding again must not duplicate the section
+	if again := AddToBody(body, tickets); again != body {
+		t.Errorf("Expected body to be unchanged when section exists, got %q", again)
+	}
+
+	if unchanged := AddToBody("body", nil); unchanged != "body" {
+		t.Errorf("Expected body to be unchanged without tickets, got %q", unchanged)
+	}
+}
+
+func TestPrefixTitle(t *testing.T) {
+	tickets := []Ticket{
+		{Key: "ENG-123"},
+		{Key: "https://example.com/T-1", URL: "https://example.com/T-1"},
+	}
+
+	if title := PrefixTitle("Fix login", tickets); title != "[ENG-123] Fix login" {
+		t.Errorf("Expected '[ENG-123] Fix login', got %q", title)
+	}
+	if title := PrefixTitle("ENG-123: Fix login", tickets); title != "ENG-123: Fix login" {
+		t.Errorf("Expected title already mentioning the key to be unchanged, got %q", title)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	if err := Enforce("error", nil); err == nil || !strings.Contains(err.Error(), "requires a ticket") {
+		t.Errorf("Expected requirement error, got: %v", err)
+	}
+	if err := Enforce("warn", nil); err != nil {
+		t.Errorf("Expected only a warning, got: %v", err)
+	}
+	if err := Enforce("error", []Ticket{{Key: "#1"}}); err != nil {
+		t.Errorf("Expected no error when a ticket exists, got: %v", err)
+	}
+}
+
+func TestBranchKey(t *testing.T) {
+	tickets := []Ticket{
+		{Key: "https://example.com/T-1", URL: "https://example.com/T-1"},
+		{Key: "ENG-123", URL: "https://linear.app/acme/issue/ENG-123"},
+	}
+	if key := BranchKey(tickets); key != "ENG-123" {
+		t.Errorf("Expected 'ENG-123', got %q", key)
+	}
+	if key := BranchKey(nil); key != "" {
+		t.Errorf("Expected no key without tickets, got %q", key)
+	}
+}
@@ -1,24 +1,88 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 
 	review "github.com/jtamagnan/git-utils/review/lib"
 	"github.com/jtamagnan/git-utils/review/lib/config"
+	"github.com/jtamagnan/git-utils/review/lib/logging"
 	"github.com/spf13/cobra"
 )
 
+// addOutputFlag adds the --output flag to a command that produces a Result
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "text", "Output format: 'text' or 'json'. With json, logs are written to stderr")
+}
+
+// setupOutput validates --output
+func setupOutput(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return nil
+	}
+	switch flag.Value.String() {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid --output %q: expected text or json", flag.Value.String())
+	}
+	return nil
+}
+
+// isJSONOutput reports whether --output json was requested
+func isJSONOutput(cmd *cobra.Command) bool {
+	output, _ := cmd.Flags().GetString("output")
+	return output == "json"
+}
+
+// progressOutput returns where progress messages are printed: stderr with
+// --output json, so that stdout only carries the result, stdout otherwise
+func progressOutput(cmd *cobra.Command) io.Writer {
+	if isJSONOutput(cmd) {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// writeJSONResult writes the result to stdout if --output json was requested
+func writeJSONResult(cmd *cobra.Command, result *review.Result) (bool, error) {
+	if !isJSONOutput(cmd) {
+		return false, nil
+	}
+	return true, result.WriteJSON(os.Stdout)
+}
+
+// closeLog closes the --log-file, if any
+var closeLog = func() {}
+
+// setupLogging installs the logger configured by --log-level and --log-file
+func setupLogging(cmd *cobra.Command) error {
+	level, _ := cmd.Flags().GetString("log-level")
+	logFile, _ := cmd.Flags().GetString("log-file")
+	closeFn, err := logging.Setup(level, logFile)
+	if err != nil {
+		return err
+	}
+	closeLog = closeFn
+	return nil
+}
+
 func runE(cmd *cobra.Command, args []string) error {
 	parsedArgs, err := config.ParseArgs(cmd, args)
 	if err != nil {
 		return err
 	}
 
-	err = review.Review(parsedArgs)
+	parsedArgs.Output = progressOutput(cmd)
+	result, err := review.Review(parsedArgs)
 	if err != nil {
 		return err
 	}
-	return nil
+	_, err = writeJSONResult(cmd, result)
+	return err
 }
 
 func stackRunE(cmd *cobra.Command, args []string) error {
@@ -27,25 +91,147 @@ func stackRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	err = review.Stack(parsedArgs)
+	parsedArgs.Output = progressOutput(cmd)
+	result, err := review.Stack(parsedArgs)
+	if err != nil {
+		return err
+	}
+	_, err = writeJSONResult(cmd, result)
+	return err
+}
+
+func statusRunE(cmd *cobra.Command, args []string) error {
+	parsedArgs, err := config.ParseStatusArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	result, err := review.Status(parsedArgs)
+	if err != nil {
+		return err
+	}
+	if written, err := writeJSONResult(cmd, result); written || err != nil {
+		return err
+	}
+	return result.WriteText(os.Stdout)
+}
+
+func commentsRunE(cmd *cobra.Command, args []string) error {
+	parsedArgs, err := config.ParseCommentsArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	err = review.Comments(parsedArgs)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func configListRunE(cmd *cobra.Command, args []string) error {
+	return config.List(os.Stdout)
+}
+
+func configGetRunE(cmd *cobra.Command, args []string) error {
+	value, err := config.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func configSetRunE(cmd *cobra.Command, args []string) error {
+	layer, err := cmd.Flags().GetString("layer")
+	if err != nil {
+		return err
+	}
+	return config.Set(layer, args[0], args[1])
+}
+
+func configValidateRunE(cmd *cobra.Command, args []string) error {
+	problems := config.Validate()
+	if len(problems) == 0 {
+		fmt.Println("Configuration is valid")
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Printf("- %s\n", problem)
+	}
+	return fmt.Errorf("found %d configuration problem(s)", len(problems))
+}
+
+func generateConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show, change and validate git-review settings.",
+		Args:  cobra.NoArgs,
+		RunE:  configListRunE,
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List every setting with its effective value and where it comes from.",
+		Args:  cobra.NoArgs,
+		RunE:  configListRunE,
+	})
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  configGetRunE,
+	})
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a setting to a configuration layer.",
+		Args:  cobra.ExactArgs(2),
+		RunE:  configSetRunE,
+	}
+	setCmd.Flags().String("layer", "git", "Layer to write to: "+strings.Join(config.WritableLayers, ", "))
+	configCmd.AddCommand(setCmd)
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:          "validate",
+		Short:        "Check config files and git config for unknown keys and invalid values.",
+		Args:         cobra.NoArgs,
+		RunE:         configValidateRunE,
+		SilenceUsage: true,
+	})
+
+	return configCmd
+}
+
 func generateCommand() *cobra.Command {
 	var rootCmd = &cobra.Command{
 		Use:   "git-review",
 		Short: "Open a pull request for this repository.",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := setupOutput(cmd); err != nil {
+				return err
+			}
+			if err := setupLogging(cmd); err != nil {
+				return err
+			}
+			config.BindFlags(cmd)
 			config.InitConfig()
+			// --verbose also enables debug logs, unless a level was given
+			if config.Verbose() && !cmd.Flags().Changed("log-level") {
+				logging.SetLevel(slog.LevelDebug)
+			}
+			return nil
 		},
 		RunE: runE,
 	}
 
+	rootCmd.PersistentFlags().String("log-level", "info", "Log level: "+strings.Join(logging.Levels, ", "))
+	rootCmd.PersistentFlags().String("log-file", "", "Write logs to this file instead of stderr")
+
 	// Set up flags using the config library
 	config.SetupFlags(rootCmd)
+	addOutputFlag(rootCmd)
 
 	// Add stack subcommand
 	stackCmd := &cobra.Command{
@@ -54,14 +240,39 @@ func generateCommand() *cobra.Command {
 		RunE:  stackRunE,
 	}
 	config.SetupStackFlags(stackCmd)
+	addOutputFlag(stackCmd)
 	rootCmd.AddCommand(stackCmd)
 
+	// Add status subcommand
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the pull requests of the commits between the parent branch and HEAD.",
+		Args:  cobra.NoArgs,
+		RunE:  statusRunE,
+	}
+	config.SetupStatusFlags(statusCmd)
+	addOutputFlag(statusCmd)
+	rootCmd.AddCommand(statusCmd)
+
+	// Add comments subcommand
+	commentsCmd := &cobra.Command{
+		Use:   "comments",
+		Short: "Show review threads for the current PR or stack, mapped to local file lines.",
+		RunE:  commentsRunE,
+	}
+	config.SetupCommentsFlags(commentsCmd)
+	rootCmd.AddCommand(commentsCmd)
+
+	// Add config subcommand
+	rootCmd.AddCommand(generateConfigCommand())
+
 	return rootCmd
 }
 
 func main() {
 	rootCmd := generateCommand()
 	err := rootCmd.Execute()
+	closeLog()
 	if err != nil {
 		os.Exit(1)
 	}
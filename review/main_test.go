@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"testing"
 
 	review "github.com/jtamagnan/git-utils/review/lib"
@@ -38,3 +39,30 @@ func TestParsedArgsStructure(t *testing.T) {
 		t.Errorf("Expected ['alice', 'bob'], got %v", args.Reviewers)
 	}
 }
+
+func TestProgressOutput(t *testing.T) {
+	stdout := os.Stdout
+	tests := []struct {
+		output   string
+		expected *os.File
+	}{
+		{"text", os.Stdout},
+		{"json", os.Stderr},
+	}
+
+	for _, tt := range tests {
+		cmd := generateCommand()
+		if err := cmd.Flags().Set("output", tt.output); err != nil {
+			t.Fatal(err)
+		}
+		if err := setupOutput(cmd); err != nil {
+			t.Fatalf("setupOutput failed for %s: %v", tt.output, err)
+		}
+		if os.Stdout != stdout {
+			t.Fatalf("setupOutput replaced os.Stdout for %s", tt.output)
+		}
+		if got := progressOutput(cmd); got != tt.expected {
+			t.Errorf("progressOutput() for %s = %v, expected %v", tt.output, got, tt.expected)
+		}
+	}
+}